@@ -0,0 +1,68 @@
+package job
+
+import (
+	"fmt"
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/app/service"
+	"arek-muhammadiyah-be/config"
+	"arek-muhammadiyah-be/helper"
+	"time"
+)
+
+// ExpireCards flips issued cards past their expiry date to expired.
+func ExpireCards() error {
+	userRepo := repository.NewUserRepository()
+	notificationSvc := service.NewNotificationService()
+
+	users, err := userRepo.GetExpiredCards(time.Now())
+	if err != nil {
+		return err
+	}
+
+	note := "card expired"
+	for _, user := range users {
+		change := model.CardStatusHistory{
+			UserID:     user.ID,
+			FromStatus: user.CardStatus,
+			ToStatus:   model.CardStatusExpired,
+			Note:       &note,
+		}
+		// One member per transaction so a single conflict does not hold back the rest
+		if err := userRepo.UpdateCardStatuses([]model.CardStatusHistory{change}, nil); err != nil {
+			config.Logger.Printf("expire card %s: %v", user.ID, err)
+			continue
+		}
+
+		notificationSvc.Notify(user.ID, "card_expired", "Membership card expired",
+			"Your membership card has expired. Request a renewal from the app.")
+	}
+
+	return nil
+}
+
+// SendCardReminders notifies members whose card expires within
+// CARD_REMINDER_DAYS. Each card is reminded once per issue.
+func SendCardReminders() error {
+	userRepo := repository.NewUserRepository()
+	notificationSvc := service.NewNotificationService()
+
+	now := time.Now()
+	days := helper.AtoiDefault(config.AppConfig.CardReminderDays, 30)
+	users, err := userRepo.GetCardsDueForReminder(now, now.AddDate(0, 0, days))
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		notificationSvc.Notify(user.ID, "card_expiring", "Membership card expiring soon",
+			fmt.Sprintf("Your membership card expires on %s. Request a renewal from the app.",
+				user.CardExpiresAt.Format("02-01-2006")))
+
+		if err := userRepo.MarkCardReminderSent(user.ID, now); err != nil {
+			config.Logger.Printf("mark reminder %s: %v", user.ID, err)
+		}
+	}
+
+	return nil
+}
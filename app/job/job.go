@@ -0,0 +1,24 @@
+package job
+
+import (
+	"arek-muhammadiyah-be/config"
+	"time"
+)
+
+// Start launches the background jobs. Each job runs once at startup and
+// then on its own interval for the lifetime of the process.
+func Start() {
+	go runEvery(24*time.Hour, "purge trash", PurgeTrash)
+	go runEvery(24*time.Hour, "expire cards", ExpireCards)
+	go runEvery(24*time.Hour, "card reminders", SendCardReminders)
+	go runEvery(15*time.Minute, "ticket SLA escalation", EscalateTicketSLAs)
+}
+
+func runEvery(interval time.Duration, name string, fn func() error) {
+	for {
+		if err := fn(); err != nil {
+			config.Logger.Printf("job %s failed: %v", name, err)
+		}
+		time.Sleep(interval)
+	}
+}
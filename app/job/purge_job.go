@@ -0,0 +1,46 @@
+package job
+
+import (
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/config"
+	"arek-muhammadiyah-be/helper"
+	"time"
+)
+
+type purger interface {
+	PurgeDeleted(before time.Time) (int64, error)
+}
+
+// PurgeTrash permanently removes rows that have been soft deleted for
+// longer than TRASH_RETENTION_DAYS. Children are purged before the users,
+// villages and categories they reference. Rows still referenced by live
+// records are skipped and stay in the trash.
+func PurgeTrash() error {
+	days := helper.AtoiDefault(config.AppConfig.TrashRetentionDays, 30)
+	before := time.Now().AddDate(0, 0, -days)
+
+	purgers := []struct {
+		name string
+		repo purger
+	}{
+		{"tickets", repository.NewTicketRepository()},
+		{"documents", repository.NewDocumentRepository()},
+		{"articles", repository.NewArticleRepository()},
+		{"users", repository.NewUserRepository()},
+		{"villages", repository.NewVillageRepository()},
+		{"categories", repository.NewCategoryRepository()},
+	}
+
+	for _, p := range purgers {
+		count, err := p.repo.PurgeDeleted(before)
+		if err != nil {
+			config.Logger.Printf("purge %s: %v", p.name, err)
+			continue
+		}
+		if count > 0 {
+			config.Logger.Printf("purged %d %s", count, p.name)
+		}
+	}
+
+	return nil
+}
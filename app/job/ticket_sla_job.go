@@ -0,0 +1,58 @@
+package job
+
+import (
+	"fmt"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/app/service"
+	"arek-muhammadiyah-be/config"
+	"arek-muhammadiyah-be/helper"
+	"time"
+)
+
+// EscalateTicketSLAs flags open tickets that missed their first response
+// or resolution target and escalates each ticket once to the category
+// supervisor, telling the assignee as well.
+func EscalateTicketSLAs() error {
+	ticketRepo := repository.NewTicketRepository()
+	notificationSvc := service.NewNotificationService()
+
+	now := time.Now()
+	tickets, err := ticketRepo.GetSLABreaches(now)
+	if err != nil {
+		return err
+	}
+
+	for _, ticket := range tickets {
+		firstResponse := ticket.FirstRespondedAt == nil && ticket.FirstResponseBreachedAt == nil &&
+			ticket.FirstResponseDueAt != nil && ticket.FirstResponseDueAt.Before(now)
+		resolution := ticket.ResolutionBreachedAt == nil &&
+			ticket.ResolutionDueAt != nil && ticket.ResolutionDueAt.Before(now)
+
+		missed := "resolution"
+		if firstResponse {
+			missed = "first response"
+		}
+		message := fmt.Sprintf("Ticket #%d \"%s\" missed its %s target.", ticket.ID, ticket.Title, missed)
+
+		var escalatedToID *string
+		if supervisorID := helper.TicketSupervisor(ticket.Category); supervisorID != "" && ticket.EscalatedAt == nil {
+			escalatedToID = &supervisorID
+		}
+
+		if err := ticketRepo.MarkSLABreach(ticket.ID, firstResponse, resolution, escalatedToID, now); err != nil {
+			config.Logger.Printf("flag SLA breach on ticket %d: %v", ticket.ID, err)
+			continue
+		}
+
+		if escalatedToID != nil {
+			notificationSvc.Notify(*escalatedToID, "ticket_escalated", "Ticket escalated", message)
+		} else if ticket.EscalatedAt == nil {
+			config.Logger.Printf("ticket %d breached its SLA but has no supervisor to escalate to", ticket.ID)
+		}
+		if ticket.AssigneeID != nil {
+			notificationSvc.Notify(*ticket.AssigneeID, "ticket_sla_breached", "Ticket past its SLA", message)
+		}
+	}
+
+	return nil
+}
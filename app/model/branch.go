@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+// Branch levels follow the Muhammadiyah structure: Pimpinan Ranting at
+// village level, Cabang at district level and Daerah at regency level.
+const (
+	BranchLevelPRM = "prm"
+	BranchLevelPCM = "pcm"
+	BranchLevelPDM = "pdm"
+)
+
+// BranchParentLevel is the level a branch's parent must have.
+var BranchParentLevel = map[string]string{
+	BranchLevelPRM: BranchLevelPCM,
+	BranchLevelPCM: BranchLevelPDM,
+	BranchLevelPDM: "",
+}
+
+type Branch struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null"`
+	Level     string    `json:"level" gorm:"not null;index"`
+	ParentID  *uint     `json:"parent_id" gorm:"index"`
+	Address   *string   `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	Parent   *Branch   `json:"parent,omitempty" gorm:"foreignKey:ParentID"`
+	Children []Branch  `json:"children,omitempty" gorm:"foreignKey:ParentID"`
+	Villages []Village `json:"villages,omitempty" gorm:"foreignKey:BranchID"`
+}
+
+// Position is an office such as ketua, sekretaris or bendahara. Unless
+// MultipleHolders is set, a branch has at most one officer in the
+// position at any time.
+type Position struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	Name            string    `json:"name" gorm:"unique;not null"`
+	SortOrder       int       `json:"sort_order" gorm:"default:0"`
+	MultipleHolders bool      `json:"multiple_holders" gorm:"default:false"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// OfficerTerm is a member holding a position in a branch. An open
+// EndDate means the term is still running.
+type OfficerTerm struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	BranchID   uint       `json:"branch_id" gorm:"not null;index"`
+	PositionID uint       `json:"position_id" gorm:"not null;index"`
+	UserID     string     `json:"user_id" gorm:"not null;index"`
+	StartDate  time.Time  `json:"start_date" gorm:"type:date;not null"`
+	EndDate    *time.Time `json:"end_date" gorm:"type:date"`
+	Note       *string    `json:"note"`
+	CreatedBy  *string    `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Relations
+	Branch   *Branch   `json:"branch,omitempty" gorm:"foreignKey:BranchID"`
+	Position *Position `json:"position,omitempty" gorm:"foreignKey:PositionID"`
+	User     *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// IsCurrent reports whether the term covers the given day. Start and end
+// dates are whole days, so a term is still current on its last day.
+func (t *OfficerTerm) IsCurrent(now time.Time) bool {
+	today := calendarDay(now)
+	return !calendarDay(t.StartDate).After(today) &&
+		(t.EndDate == nil || !calendarDay(*t.EndDate).Before(today))
+}
+
+// calendarDay drops the time of day and zone, keeping the date as written.
+func calendarDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
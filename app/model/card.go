@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+const (
+	CardStatusPending   = "pending"
+	CardStatusApproved  = "approved"
+	CardStatusRejected  = "rejected"
+	CardStatusPrinted   = "printed"
+	CardStatusDelivered = "delivered"
+	CardStatusExpired   = "expired"
+)
+
+// CardStatusTransitions lists the statuses a card may move to from each
+// status. Printed, delivered and expired cards go back to approved when a
+// renewal is approved, so the card is reprinted.
+var CardStatusTransitions = map[string][]string{
+	CardStatusPending:   {CardStatusApproved, CardStatusRejected},
+	CardStatusApproved:  {CardStatusPrinted, CardStatusRejected},
+	CardStatusRejected:  {CardStatusPending},
+	CardStatusPrinted:   {CardStatusDelivered, CardStatusExpired, CardStatusApproved},
+	CardStatusDelivered: {CardStatusExpired, CardStatusApproved},
+	CardStatusExpired:   {CardStatusApproved},
+}
+
+// ApprovedCardStatuses are the statuses of members whose membership has
+// been approved, whether or not the card has been printed yet.
+var ApprovedCardStatuses = []string{CardStatusApproved, CardStatusPrinted, CardStatusDelivered}
+
+func IsValidCardStatus(status string) bool {
+	_, ok := CardStatusTransitions[status]
+	return ok
+}
+
+func CanTransitionCardStatus(from, to string) bool {
+	for _, next := range CardStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+type CardStatusHistory struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     string    `json:"user_id" gorm:"not null;index"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status" gorm:"not null"`
+	ChangedBy  *string   `json:"changed_by"`
+	Note       *string   `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CardRenewal is a member's request to renew an expired or expiring card.
+// Approval moves the card back to approved so it is reprinted.
+type CardRenewal struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     string     `json:"user_id" gorm:"not null;index"`
+	Note       *string    `json:"note"`
+	Status     string     `json:"status" gorm:"default:'pending';index"`
+	ReviewedBy *string    `json:"reviewed_by"`
+	ReviewNote *string    `json:"review_note"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Relations
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
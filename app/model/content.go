@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Category struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
@@ -8,8 +12,16 @@ type Category struct {
 	Description *string   `json:"description"`
 	Color       string    `json:"color" gorm:"default:'#10B981'"`
 	IsActive    bool      `json:"is_active" gorm:"default:true"`
+
+	// SLA targets for tickets in this category. Nil falls back to
+	// TICKET_FIRST_RESPONSE_HOURS and TICKET_RESOLUTION_HOURS, and breaches
+	// escalate to TICKET_SUPERVISOR_ID when no supervisor is set.
+	FirstResponseHours *int    `json:"first_response_hours"`
+	ResolutionHours    *int    `json:"resolution_hours"`
+	SupervisorID       *string `json:"supervisor_id"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 	
 	// Relations
 	Articles []Article `json:"articles,omitempty"`
@@ -27,6 +39,7 @@ type Article struct {
 	IsPublished   bool      `json:"is_published" gorm:"default:false"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 	
 	// Relations
 	User     *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
@@ -54,10 +67,32 @@ type Ticket struct {
 	CreatedAt   time.Time    `json:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at"`
 	ResolvedAt  *time.Time   `json:"resolved_at"`
+
+	// When each side last read the message thread
+	ReporterReadAt *time.Time `json:"reporter_read_at"`
+	StaffReadAt    *time.Time `json:"staff_read_at"`
+
+	// Staff member handling the ticket. AssignedByID is nil when an
+	// assignment rule picked them.
+	AssigneeID   *string    `json:"assignee_id" gorm:"index"`
+	AssignedByID *string    `json:"assigned_by_id"`
+	AssignedAt   *time.Time `json:"assigned_at"`
+
+	// SLA tracking. Due dates are set when the ticket is created; the
+	// escalation job stamps the breaches.
+	FirstRespondedAt        *time.Time `json:"first_responded_at"`
+	FirstResponseDueAt      *time.Time `json:"first_response_due_at" gorm:"index"`
+	ResolutionDueAt         *time.Time `json:"resolution_due_at" gorm:"index"`
+	FirstResponseBreachedAt *time.Time `json:"first_response_breached_at"`
+	ResolutionBreachedAt    *time.Time `json:"resolution_breached_at"`
+	EscalatedAt             *time.Time `json:"escalated_at"`
+	EscalatedToID           *string    `json:"escalated_to_id"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 	
 	// Relations
 	User     *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	Category *Category `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+	Assignee *User     `json:"assignee,omitempty" gorm:"foreignKey:AssigneeID"`
 }
 
 type Document struct {
@@ -71,6 +106,7 @@ type Document struct {
 	MimeType    *string   `json:"mime_type"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 	
 	// Relations
 	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
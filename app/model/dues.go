@@ -0,0 +1,66 @@
+package model
+
+import "time"
+
+const (
+	DuesFrequencyMonthly = "monthly"
+	DuesFrequencyYearly  = "yearly"
+)
+
+const (
+	PaymentMethodCash     = "cash"
+	PaymentMethodTransfer = "transfer"
+	PaymentMethodQRIS     = "qris"
+)
+
+// DuesSchedule sets the dues amount per period. A schedule without a
+// village applies to every village that has no schedule of its own.
+type DuesSchedule struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Name      string     `json:"name" gorm:"not null"`
+	Amount    int64      `json:"amount" gorm:"not null"`
+	Frequency string     `json:"frequency" gorm:"default:'monthly'"`
+	VillageID *uint      `json:"village_id" gorm:"index"`
+	StartDate time.Time  `json:"start_date" gorm:"type:date;not null"`
+	EndDate   *time.Time `json:"end_date" gorm:"type:date"`
+	IsActive  bool       `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// Relations
+	Village *Village `json:"village,omitempty" gorm:"foreignKey:VillageID"`
+}
+
+// AppliesTo reports whether payments of a member in the village may be
+// booked against the schedule at now: it is active, has started, has not
+// ended, and is either global or the village's own.
+func (s *DuesSchedule) AppliesTo(villageID *uint, now time.Time) bool {
+	today := calendarDay(now)
+	if !s.IsActive || calendarDay(s.StartDate).After(today) {
+		return false
+	}
+	if s.EndDate != nil && calendarDay(*s.EndDate).Before(today) {
+		return false
+	}
+	return s.VillageID == nil || (villageID != nil && *s.VillageID == *villageID)
+}
+
+// DuesPayment records money received from a member. One payment may cover
+// several periods of its schedule.
+type DuesPayment struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	UserID        string    `json:"user_id" gorm:"not null;index"`
+	ScheduleID    uint      `json:"schedule_id" gorm:"not null;index"`
+	Periods       int       `json:"periods" gorm:"default:1"`
+	Amount        int64     `json:"amount" gorm:"not null"`
+	Method        string    `json:"method" gorm:"not null"`
+	ReceiptNumber string    `json:"receipt_number" gorm:"unique;not null"`
+	PaidAt        time.Time `json:"paid_at" gorm:"index"`
+	RecordedBy    *string   `json:"recorded_by"`
+	Note          *string   `json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Relations
+	User     *User         `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Schedule *DuesSchedule `json:"schedule,omitempty" gorm:"foreignKey:ScheduleID"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// ErasureRequest is a member's request under UU PDP to have their personal
+// data removed. Approval anonymizes the account instead of deleting it so
+// village and card statistics stay correct.
+type ErasureRequest struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     string     `json:"user_id" gorm:"not null;index"`
+	Reason     *string    `json:"reason"`
+	Status     string     `json:"status" gorm:"default:'pending';index"`
+	ReviewedBy *string    `json:"reviewed_by"`
+	ReviewNote *string    `json:"review_note"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Relations
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+const (
+	HouseholdRelationHead   = "head"
+	HouseholdRelationSpouse = "spouse"
+	HouseholdRelationChild  = "child"
+	HouseholdRelationParent = "parent"
+	HouseholdRelationOther  = "other"
+)
+
+// Household groups members that share a Kartu Keluarga. The head of the
+// family is the member whose HouseholdRelation is HouseholdRelationHead.
+type Household struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	KKNumber  string    `json:"kk_number" gorm:"unique;not null"`
+	VillageID *uint     `json:"village_id"`
+	Address   *string   `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	Village *Village `json:"village,omitempty" gorm:"foreignKey:VillageID"`
+	Members []User   `json:"members,omitempty" gorm:"foreignKey:HouseholdID"`
+}
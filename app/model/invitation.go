@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Invitation lets a member created without a password set one and
+// activate the account. Only a hash of the token is stored; the token
+// itself is handed to the admin once, when the invitation is (re)sent.
+type Invitation struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     string     `json:"user_id" gorm:"not null;index"`
+	TokenHash  string     `json:"-" gorm:"unique;not null"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedBy  *string    `json:"created_by"`
+	SendCount  int        `json:"send_count" gorm:"default:1"`
+	LastSentAt time.Time  `json:"last_sent_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Relations
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// IsPending reports whether the invitation can still be accepted.
+func (i *Invitation) IsPending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// MemberSequence holds the last member number handed out for a village in
+// a year. Members without a village share the row with VillageID 0.
+type MemberSequence struct {
+	VillageID uint      `json:"village_id" gorm:"primaryKey;autoIncrement:false"`
+	Year      int       `json:"year" gorm:"primaryKey;autoIncrement:false"`
+	LastValue int       `json:"last_value" gorm:"not null;default:0"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// Notification is an in-app message for a member, shown by the web and
+// mobile clients.
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    string     `json:"user_id" gorm:"not null;index"`
+	Type      string     `json:"type" gorm:"not null"`
+	Title     string     `json:"title" gorm:"not null"`
+	Message   string     `json:"message" gorm:"not null"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
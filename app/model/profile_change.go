@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+const (
+	ChangeRequestPending  = "pending"
+	ChangeRequestApproved = "approved"
+	ChangeRequestRejected = "rejected"
+)
+
+// ProfileChangeRequest holds a member's requested change to a field that
+// needs admin approval before it is applied to User.
+type ProfileChangeRequest struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     string     `json:"user_id" gorm:"not null;index"`
+	NIK        *string    `json:"nik"`
+	VillageID  *uint      `json:"village_id"`
+	Reason     *string    `json:"reason"`
+	Status     string     `json:"status" gorm:"default:'pending';index"`
+	ReviewedBy *string    `json:"reviewed_by"`
+	ReviewNote *string    `json:"review_note"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Relations
+	User    *User    `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Village *Village `json:"village,omitempty" gorm:"foreignKey:VillageID"`
+}
@@ -0,0 +1,85 @@
+package model
+
+import "time"
+
+// Administrative regions follow the Kemendagri codes, which nest by
+// prefix: province 35, regency 35.78, district 35.78.01. Villages keep
+// their own Code and point at their district.
+
+const (
+	RegionLevelProvince = "province"
+	RegionLevelRegency  = "regency"
+	RegionLevelDistrict = "district"
+	RegionLevelVillage  = "village"
+)
+
+func IsValidRegionLevel(level string) bool {
+	switch level {
+	case RegionLevelProvince, RegionLevelRegency, RegionLevelDistrict, RegionLevelVillage:
+		return true
+	}
+	return false
+}
+
+const (
+	RegencyTypeKabupaten = "kabupaten"
+	RegencyTypeKota      = "kota"
+)
+
+type Province struct {
+	Code      string    `json:"code" gorm:"primaryKey;size:2"`
+	Name      string    `json:"name" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	Regencies []Regency `json:"regencies,omitempty" gorm:"foreignKey:ProvinceCode"`
+}
+
+type Regency struct {
+	Code         string    `json:"code" gorm:"primaryKey;size:5"`
+	ProvinceCode string    `json:"province_code" gorm:"not null;index"`
+	Name         string    `json:"name" gorm:"not null"`
+	Type         string    `json:"type" gorm:"default:'kabupaten'"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Relations
+	Province  *Province  `json:"province,omitempty" gorm:"foreignKey:ProvinceCode"`
+	Districts []District `json:"districts,omitempty" gorm:"foreignKey:RegencyCode"`
+}
+
+type District struct {
+	Code        string    `json:"code" gorm:"primaryKey;size:8"`
+	RegencyCode string    `json:"regency_code" gorm:"not null;index"`
+	Name        string    `json:"name" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relations
+	Regency  *Regency  `json:"regency,omitempty" gorm:"foreignKey:RegencyCode"`
+	Villages []Village `json:"villages,omitempty" gorm:"foreignKey:DistrictCode"`
+}
+
+// RegionFilter narrows a query to villages under one region. The most
+// specific code that is set wins.
+type RegionFilter struct {
+	ProvinceCode string
+	RegencyCode  string
+	DistrictCode string
+}
+
+func (f *RegionFilter) IsEmpty() bool {
+	return f == nil || (f.ProvinceCode == "" && f.RegencyCode == "" && f.DistrictCode == "")
+}
+
+// RegionSummary counts members and tickets for one region at the level
+// requested from the dashboard.
+type RegionSummary struct {
+	Code          string `json:"code"`
+	Name          string `json:"name"`
+	TotalVillages int64  `json:"total_villages"`
+	TotalMembers  int64  `json:"total_members"`
+	TotalApproved int64  `json:"total_approved"`
+	TotalTickets  int64  `json:"total_tickets"`
+}
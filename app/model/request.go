@@ -1,14 +1,19 @@
 package model
 
+import "encoding/json"
+
 type LoginRequest struct {
 	ID       string `json:"id" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
 
+// CreateUserRequest.ID is optional; when empty a member number is
+// generated from MEMBER_NUMBER_FORMAT. Admins may leave Password empty to
+// send the member an invitation instead.
 type CreateUserRequest struct {
-	ID         string  `json:"id" validate:"required"`
+	ID         string  `json:"id"`
 	Name       string  `json:"name" validate:"required"`
-	Password   string  `json:"password" validate:"required,min=6"`
+	Password   string  `json:"password" validate:"omitempty,min=6"`
 	Telp       *string `json:"telp"`
 	RoleID     *uint   `json:"role_id"`
 	VillageID  *uint   `json:"village_id"`
@@ -16,6 +21,29 @@ type CreateUserRequest struct {
 	Address    *string `json:"address"`
 	CardStatus *string `json:"card_status"`
 	IsMobile   *bool   `json:"is_mobile"`
+	UserProfileRequest
+
+	// Either the recruiter's member ID or their personal referral code
+	ReferredBy   *string `json:"referred_by"`
+	ReferralCode *string `json:"referral_code"`
+
+	// Row is the line in an import file, for error messages
+	Row int `json:"-"`
+}
+
+// UserProfileRequest holds the optional membership registry fields shared
+// by the create, update and import paths. BirthDate is a date string such
+// as 2006-01-02 or 02/01/2006.
+type UserProfileRequest struct {
+	BirthPlace    *string `json:"birth_place"`
+	BirthDate     *string `json:"birth_date"`
+	Gender        *string `json:"gender"`
+	Occupation    *string `json:"occupation"`
+	Education     *string `json:"education"`
+	MaritalStatus *string `json:"marital_status"`
+	Ranting       *string `json:"ranting"`
+	Cabang        *string `json:"cabang"`
+	Daerah        *string `json:"daerah"`
 }
 
 type UpdateUserRequest struct {
@@ -26,6 +54,21 @@ type UpdateUserRequest struct {
 	NIK        *string `json:"nik"`
 	Address    *string `json:"address"`
 	CardStatus *string `json:"card_status"`
+	UserProfileRequest
+}
+
+type UserFilter struct {
+	RegionFilter
+	VillageID     *uint
+	IsActive      *bool
+	CardStatus    string
+	Gender        string
+	Occupation    string
+	Education     string
+	MaritalStatus string
+	Ranting       string
+	Cabang        string
+	Daerah        string
 }
 
 type CreateArticleRequest struct {
@@ -45,6 +88,27 @@ type CreateTicketRequest struct {
 type UpdateTicketRequest struct {
 	Status     *TicketStatus `json:"status"`
 	Resolution *string       `json:"resolution"`
+	Note       *string       `json:"note"`
+}
+
+type ReopenTicketRequest struct {
+	Note *string `json:"note"`
+}
+
+type AssignTicketRequest struct {
+	AssigneeID *string `json:"assignee_id"`
+}
+
+type TicketAssignmentRuleRequest struct {
+	CategoryID *uint    `json:"category_id"`
+	VillageID  *uint    `json:"village_id"`
+	IsActive   *bool    `json:"is_active"`
+	StaffIDs   []string `json:"staff_ids"`
+}
+
+type CreateTicketMessageRequest struct {
+	Body       string `json:"body" validate:"required"`
+	IsInternal bool   `json:"is_internal"`
 }
 
 type CreateDocumentRequest struct {
@@ -62,6 +126,17 @@ type CreateVillageRequest struct {
 	Description *string `json:"description"`
 	Color       *string `json:"color"`
 	IsActive    *bool   `json:"is_active"`
+	DistrictCode *string `json:"district_code"`
+}
+
+// CreateRegionRequest is shared by provinces, regencies and districts.
+// ParentCode is the province of a regency or the regency of a district;
+// Type only applies to regencies.
+type CreateRegionRequest struct {
+	Code       string  `json:"code" validate:"required"`
+	Name       string  `json:"name" validate:"required"`
+	ParentCode string  `json:"parent_code"`
+	Type       *string `json:"type"`
 }
 
 type CreateCategoryRequest struct {
@@ -69,4 +144,145 @@ type CreateCategoryRequest struct {
 	Description *string `json:"description"`
 	Color       *string `json:"color"`
 	IsActive    *bool   `json:"is_active"`
-}
\ No newline at end of file
+
+	FirstResponseHours *int    `json:"first_response_hours"`
+	ResolutionHours    *int    `json:"resolution_hours"`
+	SupervisorID       *string `json:"supervisor_id"`
+}
+
+type CategorySLARequest struct {
+	FirstResponseHours *int    `json:"first_response_hours"`
+	ResolutionHours    *int    `json:"resolution_hours"`
+	SupervisorID       *string `json:"supervisor_id"`
+}
+
+type CreateHouseholdRequest struct {
+	KKNumber  string  `json:"kk_number" validate:"required"`
+	VillageID *uint   `json:"village_id"`
+	Address   *string `json:"address"`
+}
+
+type HouseholdMemberRequest struct {
+	UserID   string `json:"user_id" validate:"required"`
+	Relation string `json:"relation" validate:"required"`
+}
+
+// UpdateMeRequest is the set of fields a member may change on their own
+// profile. NIK and VillageID are not applied directly; they create a
+// ProfileChangeRequest for an admin to review.
+type UpdateMeRequest struct {
+	Name          *string `json:"name"`
+	Telp          *string `json:"telp"`
+	Address       *string `json:"address"`
+	BirthPlace    *string `json:"birth_place"`
+	BirthDate     *string `json:"birth_date"`
+	Gender        *string `json:"gender"`
+	Occupation    *string `json:"occupation"`
+	Education     *string `json:"education"`
+	MaritalStatus *string `json:"marital_status"`
+
+	NIK       *string `json:"nik"`
+	VillageID *uint   `json:"village_id"`
+	Reason    *string `json:"reason"`
+}
+
+type ReviewChangeRequest struct {
+	Note *string `json:"note"`
+}
+
+// BulkCardStatusRequest selects members either by IDs or by filter and
+// moves them all to ToStatus. Unless SkipInvalid is set, one invalid
+// transition rejects the whole batch.
+type BulkCardStatusRequest struct {
+	IDs         []string `json:"ids"`
+	VillageID   *uint    `json:"village_id"`
+	CardStatus  *string  `json:"card_status"`
+	ToStatus    string   `json:"to_status" validate:"required"`
+	Note        *string  `json:"note"`
+	SkipInvalid bool     `json:"skip_invalid"`
+}
+
+type CardRenewalRequest struct {
+	Note *string `json:"note"`
+}
+
+type CreateDuesScheduleRequest struct {
+	Name      string  `json:"name" validate:"required"`
+	Amount    int64   `json:"amount" validate:"required"`
+	Frequency *string `json:"frequency"`
+	VillageID *uint   `json:"village_id"`
+	StartDate string  `json:"start_date" validate:"required"`
+	EndDate   *string `json:"end_date"`
+	IsActive  *bool   `json:"is_active"`
+}
+
+type CreateDuesPaymentRequest struct {
+	UserID        string  `json:"user_id" validate:"required"`
+	ScheduleID    *uint   `json:"schedule_id"`
+	Periods       *int    `json:"periods"`
+	Amount        *int64  `json:"amount"`
+	Method        string  `json:"method" validate:"required"`
+	ReceiptNumber string  `json:"receipt_number" validate:"required"`
+	PaidAt        *string `json:"paid_at"`
+	Note          *string `json:"note"`
+}
+
+type CreateErasureRequest struct {
+	Reason *string `json:"reason"`
+}
+
+type SuspendUserRequest struct {
+	Reason string  `json:"reason" validate:"required"`
+	Until  *string `json:"until"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+// ImportVillageRequest is one row of a village import. Row is the line or
+// feature number in the source file, for error messages.
+type ImportVillageRequest struct {
+	Row          int             `json:"row"`
+	Code         string          `json:"code"`
+	Name         *string         `json:"name"`
+	Description  *string         `json:"description"`
+	Color        *string         `json:"color"`
+	IsActive     *bool           `json:"is_active"`
+	DistrictCode *string         `json:"district_code"`
+	Boundary     json.RawMessage `json:"-"`
+}
+
+// BulkVillageStatusRequest selects villages by ID or by code.
+type BulkVillageStatusRequest struct {
+	IDs      []uint   `json:"ids"`
+	Codes    []string `json:"codes"`
+	IsActive bool     `json:"is_active"`
+}
+
+// CreateBranchRequest also links the listed villages to the branch,
+// replacing any villages it had before when VillageIDs is sent.
+type CreateBranchRequest struct {
+	Name       string  `json:"name" validate:"required"`
+	Level      string  `json:"level" validate:"required"`
+	ParentID   *uint   `json:"parent_id"`
+	Address    *string `json:"address"`
+	VillageIDs []uint  `json:"village_ids"`
+}
+
+type CreatePositionRequest struct {
+	Name            string `json:"name" validate:"required"`
+	SortOrder       *int   `json:"sort_order"`
+	MultipleHolders *bool  `json:"multiple_holders"`
+}
+
+// OfficerTermRequest dates are YYYY-MM-DD; EndDate may be left out for a
+// term that is still running.
+type OfficerTermRequest struct {
+	PositionID uint    `json:"position_id" validate:"required"`
+	UserID     string  `json:"user_id" validate:"required"`
+	StartDate  string  `json:"start_date" validate:"required"`
+	EndDate    *string `json:"end_date"`
+	Note       *string `json:"note"`
+}
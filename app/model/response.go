@@ -47,5 +47,135 @@ type DashboardStats struct {
 
 type VillageWithUserCount struct {
 	Village
-	TotalUsers int `json:"total_users"`
-}
\ No newline at end of file
+	TotalUsers      int `json:"total_users"`
+	TotalHouseholds int `json:"total_households"`
+}
+
+type BulkCardStatusResult struct {
+	UserID     string `json:"user_id"`
+	FromStatus string `json:"from_status,omitempty"`
+	ToStatus   string `json:"to_status"`
+	Success    bool   `json:"success"`
+	Message    string `json:"message,omitempty"`
+}
+
+type DuesArrears struct {
+	UserID        string        `json:"user_id"`
+	Schedule      *DuesSchedule `json:"schedule"`
+	DuePeriods    int           `json:"due_periods"`
+	PaidPeriods   int           `json:"paid_periods"`
+	UnpaidPeriods int           `json:"unpaid_periods"`
+	AmountDue     int64         `json:"amount_due"`
+}
+
+type VillageDuesSummary struct {
+	VillageID     *uint  `json:"village_id"`
+	VillageName   string `json:"village_name"`
+	TotalAmount   int64  `json:"total_amount"`
+	TotalPayments int64  `json:"total_payments"`
+	PayingMembers int64  `json:"paying_members"`
+	TotalMembers  int64  `json:"total_members"`
+}
+
+type ReferralLeaderboardEntry struct {
+	UserID        string `json:"user_id"`
+	Name          string `json:"name"`
+	VillageID     *uint  `json:"village_id"`
+	VillageName   string `json:"village_name"`
+	TotalReferred int64  `json:"total_referred"`
+	TotalApproved int64  `json:"total_approved"`
+}
+
+type VillageReferralEntry struct {
+	VillageID     *uint  `json:"village_id"`
+	VillageName   string `json:"village_name"`
+	TotalReferred int64  `json:"total_referred"`
+	TotalApproved int64  `json:"total_approved"`
+}
+
+const (
+	ImportActionCreate    = "create"
+	ImportActionUpdate    = "update"
+	ImportActionUnchanged = "unchanged"
+	ImportActionInvalid   = "invalid"
+)
+
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// VillageImportResult is the planned or applied outcome for one import row.
+type VillageImportResult struct {
+	Row     int                    `json:"row"`
+	Code    string                 `json:"code"`
+	Action  string                 `json:"action"`
+	Changes map[string]FieldChange `json:"changes,omitempty"`
+	Message string                 `json:"message,omitempty"`
+}
+
+// VillageReferences counts what still points at a village and would be
+// left dangling if it were deleted. Tickets belong to a village through
+// their reporter, so they are covered by Members; the village coordinators
+// are the staff of its AssignmentRules.
+type VillageReferences struct {
+	Members         int64 `json:"members"`
+	Households      int64 `json:"households"`
+	DuesSchedules   int64 `json:"dues_schedules"`
+	PendingChanges  int64 `json:"pending_changes"`
+	AssignmentRules int64 `json:"assignment_rules"`
+}
+
+func (r VillageReferences) Total() int64 {
+	return r.Members + r.Households + r.DuesSchedules + r.PendingChanges + r.AssignmentRules
+}
+
+// AssigneeWorkload counts the open tickets of one staff member. Overdue
+// tickets are past their resolution due date or, without one, have been
+// open longer than TICKET_OVERDUE_DAYS.
+type AssigneeWorkload struct {
+	AssigneeID string `json:"assignee_id"`
+	Name       string `json:"name"`
+	Open       int64  `json:"open"`
+	Overdue    int64  `json:"overdue"`
+}
+
+// SLACompliance counts tickets whose target has been met or missed.
+// Tickets still inside their target are not measured yet.
+type SLACompliance struct {
+	Measured   int64   `json:"measured"`
+	Met        int64   `json:"met"`
+	Breached   int64   `json:"breached"`
+	Percentage float64 `json:"percentage"`
+}
+
+type TicketSLAStats struct {
+	FirstResponse SLACompliance `json:"first_response"`
+	Resolution    SLACompliance `json:"resolution"`
+	OpenBreached  int64         `json:"open_breached"`
+	Escalated     int64         `json:"escalated"`
+}
+
+// VillageStats breaks a village's members down along several dimensions.
+// Map keys are the card status, gender, age bracket, role name or
+// registration month (YYYY-MM); missing values count as "unknown".
+type VillageStats struct {
+	VillageID           uint                 `json:"village_id"`
+	VillageName         string               `json:"village_name"`
+	VillageCode         string               `json:"village_code"`
+	TotalMembers        int64                `json:"total_members"`
+	ByCardStatus        map[string]int64     `json:"by_card_status"`
+	ByGender            map[string]int64     `json:"by_gender"`
+	ByAgeBracket        map[string]int64     `json:"by_age_bracket"`
+	ByRole              map[string]int64     `json:"by_role"`
+	ByRegistrationMonth map[string]int64     `json:"by_registration_month"`
+	OpenTickets         int64                `json:"open_tickets"`
+	Documents           DocumentCompleteness `json:"documents"`
+}
+
+type DocumentCompleteness struct {
+	MembersWithDocuments int64   `json:"members_with_documents"`
+	MembersWithNIK       int64   `json:"members_with_nik"`
+	MembersWithPhoto     int64   `json:"members_with_photo"`
+	Percentage           float64 `json:"percentage"`
+}
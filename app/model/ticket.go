@@ -0,0 +1,81 @@
+package model
+
+import "time"
+
+// TicketStatusTransitions lists the statuses staff may move a ticket to
+// from each status. Moving a resolved or closed ticket back to in progress
+// reopens it.
+var TicketStatusTransitions = map[TicketStatus][]TicketStatus{
+	TicketStatusUnread:     {TicketStatusRead, TicketStatusInProgress, TicketStatusResolved, TicketStatusClosed},
+	TicketStatusRead:       {TicketStatusInProgress, TicketStatusResolved, TicketStatusClosed},
+	TicketStatusInProgress: {TicketStatusResolved, TicketStatusClosed},
+	TicketStatusResolved:   {TicketStatusInProgress, TicketStatusClosed},
+	TicketStatusClosed:     {TicketStatusInProgress},
+}
+
+func IsValidTicketStatus(status TicketStatus) bool {
+	_, ok := TicketStatusTransitions[status]
+	return ok
+}
+
+func CanTransitionTicketStatus(from, to TicketStatus) bool {
+	for _, next := range TicketStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinished reports whether the status counts as done, which is when
+// ResolvedAt is set.
+func (s TicketStatus) IsFinished() bool {
+	return s == TicketStatusResolved || s == TicketStatusClosed
+}
+
+type TicketStatusHistory struct {
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	TicketID   uint         `json:"ticket_id" gorm:"not null;index"`
+	FromStatus TicketStatus `json:"from_status"`
+	ToStatus   TicketStatus `json:"to_status" gorm:"not null"`
+	ChangedBy  *string      `json:"changed_by"`
+	Note       *string      `json:"note"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// TicketMessage is one post in a ticket's thread. Internal notes are only
+// shown to staff. FromStaff records which side wrote the message so read
+// receipts can be worked out per side.
+type TicketMessage struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	TicketID   uint      `json:"ticket_id" gorm:"not null;index"`
+	UserID     string    `json:"user_id" gorm:"not null"`
+	Body       string    `json:"body" gorm:"not null"`
+	IsInternal bool      `json:"is_internal" gorm:"default:false"`
+	FromStaff  bool      `json:"from_staff" gorm:"default:false"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// ReadByOtherSide is filled in when the thread is loaded
+	ReadByOtherSide bool `json:"read_by_other_side" gorm:"-"`
+
+	// Relations
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// TicketAssignmentRule hands new tickets to its staff in turn. A rule
+// covers either a category or the reporter's village, where the staff are
+// the village coordinators. Village rules win over category rules.
+type TicketAssignmentRule struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	CategoryID     *uint     `json:"category_id" gorm:"index"`
+	VillageID      *uint     `json:"village_id" gorm:"index"`
+	IsActive       bool      `json:"is_active" gorm:"default:true"`
+	LastAssigneeID *string   `json:"last_assignee_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// Relations
+	Category *Category `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+	Village  *Village  `json:"village,omitempty" gorm:"foreignKey:VillageID"`
+	Staff    []User    `json:"staff,omitempty" gorm:"many2many:ticket_assignment_rule_staff"`
+}
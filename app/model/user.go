@@ -1,6 +1,22 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
+)
+
+const (
+	MaritalStatusSingle   = "single"
+	MaritalStatusMarried  = "married"
+	MaritalStatusDivorced = "divorced"
+	MaritalStatusWidowed  = "widowed"
+)
 
 type User struct {
 	ID         string     `json:"id" gorm:"primaryKey"`
@@ -12,18 +28,64 @@ type User struct {
 	NIK        *string    `json:"nik" gorm:"unique"`
 	Address    *string    `json:"address"`
 	CardStatus string     `json:"card_status" gorm:"default:'pending'"`
+	CardIssuedAt       *time.Time `json:"card_issued_at"`
+	CardExpiresAt      *time.Time `json:"card_expires_at" gorm:"index"`
+	CardReminderSentAt *time.Time `json:"-"`
+
+	// Profile
+	BirthPlace    *string    `json:"birth_place"`
+	BirthDate     *time.Time `json:"birth_date" gorm:"type:date"`
+	Gender        *string    `json:"gender"`
+	Occupation    *string    `json:"occupation"`
+	Education     *string    `json:"education"`
+	MaritalStatus *string    `json:"marital_status"`
+
+	// Muhammadiyah hierarchy
+	Ranting *string `json:"ranting" gorm:"index"`
+	Cabang  *string `json:"cabang" gorm:"index"`
+	Daerah  *string `json:"daerah" gorm:"index"`
+
+	// Family card
+	HouseholdID       *uint   `json:"household_id"`
+	HouseholdRelation *string `json:"household_relation"`
+
 	IsMobile   bool       `json:"is_mobile" gorm:"default:false"`
+	PhotoURL      *string `json:"photo_url"`
+	PhotoThumbURL *string `json:"photo_thumb_url"`
+	AnonymizedAt  *time.Time `json:"anonymized_at,omitempty"`
+
+	// Referral
+	ReferredBy   *string `json:"referred_by" gorm:"index"`
+	ReferralCode *string `json:"referral_code" gorm:"unique"`
+
+	// Account status
+	IsActive        bool       `json:"is_active" gorm:"default:true"`
+	SuspendedReason *string    `json:"suspended_reason"`
+	SuspendedUntil  *time.Time `json:"suspended_until"`
+	SuspendedAt     *time.Time `json:"suspended_at"`
+	SuspendedBy     *string    `json:"suspended_by"`
 	CreatedAt  time.Time  `json:"created_at"`
 	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 	
 	// Relations
 	Role      *Role      `json:"role,omitempty" gorm:"foreignKey:RoleID"`
 	Village   *Village   `json:"village,omitempty" gorm:"foreignKey:VillageID"`
+	Household *Household `json:"household,omitempty" gorm:"foreignKey:HouseholdID"`
 	Articles  []Article  `json:"articles,omitempty"`
 	Tickets   []Ticket   `json:"tickets,omitempty"`
 	Documents []Document `json:"documents,omitempty"`
 }
 
+// IsSuspended reports whether the account is blocked at the given time.
+// A suspension with an end date lifts itself once that date has passed.
+func (u *User) IsSuspended(now time.Time) bool {
+	if u.IsActive {
+		return false
+	}
+	return u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil)
+}
+
 type Role struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
 	Name        string    `json:"name" gorm:"unique;not null"`
@@ -42,9 +104,22 @@ type Village struct {
 	Description *string   `json:"description"`
 	Color       string    `json:"color" gorm:"default:'#3B82F6'"`
 	IsActive    bool      `json:"is_active" gorm:"default:true"`
+	DistrictCode *string  `json:"district_code" gorm:"index"`
+	BranchID     *uint    `json:"branch_id" gorm:"index"`
+
+	// Map boundary as a GeoJSON Polygon or MultiPolygon geometry
+	Boundary    *string  `json:"-" gorm:"type:jsonb"`
+	CentroidLat *float64 `json:"centroid_lat"`
+	CentroidLng *float64 `json:"centroid_lng"`
+
+	// Set when the village was merged into another instead of deleted
+	ArchivedAt   *time.Time `json:"archived_at"`
+	MergedIntoID *uint      `json:"merged_into_id"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 	
 	// Relations
-	Users []User `json:"users,omitempty"`
+	District *District `json:"district,omitempty" gorm:"foreignKey:DistrictCode"`
+	Users    []User    `json:"users,omitempty"`
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package repository
 import (
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/database"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -52,6 +53,14 @@ func (r *ArticleRepository) GetBySlug(slug string) (*model.Article, error) {
 	return &article, err
 }
 
+// SlugExists also checks soft-deleted articles, since the unique index
+// on slug still covers them.
+func (r *ArticleRepository) SlugExists(slug string) bool {
+	var count int64
+	r.db.Unscoped().Model(&model.Article{}).Where("slug = ?", slug).Count(&count)
+	return count > 0
+}
+
 func (r *ArticleRepository) Create(article *model.Article) error {
 	return r.db.Create(article).Error
 }
@@ -64,6 +73,14 @@ func (r *ArticleRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Article{}, id).Error
 }
 
+func (r *ArticleRepository) GetByUserID(userID string) ([]model.Article, error) {
+	var articles []model.Article
+	err := r.db.Preload("Category").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").Find(&articles).Error
+	return articles, err
+}
+
 func (r *ArticleRepository) GetByCategory(categoryID uint, limit, offset int) ([]model.Article, error) {
 	var articles []model.Article
 	err := r.db.Preload("User").Preload("Category").
@@ -71,4 +88,16 @@ func (r *ArticleRepository) GetByCategory(categoryID uint, limit, offset int) ([
 		Order("created_at DESC").
 		Limit(limit).Offset(offset).Find(&articles).Error
 	return articles, err
-}
\ No newline at end of file
+}
+
+func (r *ArticleRepository) GetDeleted(limit, offset int) ([]model.Article, int64, error) {
+	return getDeleted[model.Article](r.db, limit, offset)
+}
+
+func (r *ArticleRepository) Restore(id uint) error {
+	return restore[model.Article](r.db, id)
+}
+
+func (r *ArticleRepository) PurgeDeleted(before time.Time) (int64, error) {
+	return purgeDeleted[model.Article](r.db, before)
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/database"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BranchRepository struct {
+	db *gorm.DB
+}
+
+func NewBranchRepository() *BranchRepository {
+	return &BranchRepository{
+		db: database.DB,
+	}
+}
+
+func (r *BranchRepository) GetAll(level string, parentID *uint) ([]model.Branch, error) {
+	var branches []model.Branch
+	query := r.db.Model(&model.Branch{})
+	if level != "" {
+		query = query.Where("level = ?", level)
+	}
+	if parentID != nil {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	err := query.Preload("Parent").Order("name ASC").Find(&branches).Error
+	return branches, err
+}
+
+func (r *BranchRepository) GetByID(id uint) (*model.Branch, error) {
+	var branch model.Branch
+	err := r.db.Preload("Parent").Preload("Children").Preload("Villages").
+		First(&branch, id).Error
+	return &branch, err
+}
+
+// Save creates or updates the branch and, when villageIDs is not nil,
+// makes those villages the branch's villages, in one transaction.
+func (r *BranchRepository) Save(branch *model.Branch, villageIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(branch).Error; err != nil {
+			return err
+		}
+		if villageIDs == nil {
+			return nil
+		}
+
+		err := tx.Model(&model.Village{}).Where("branch_id = ?", branch.ID).
+			Update("branch_id", nil).Error
+		if err != nil {
+			return err
+		}
+		if len(villageIDs) == 0 {
+			return nil
+		}
+		return tx.Model(&model.Village{}).Where("id IN ?", villageIDs).
+			Update("branch_id", branch.ID).Error
+	})
+}
+
+// CountDependents reports child branches and officer terms, which would
+// be orphaned if the branch were deleted.
+func (r *BranchRepository) CountDependents(id uint) (int64, error) {
+	var children, terms int64
+	if err := r.db.Model(&model.Branch{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.Model(&model.OfficerTerm{}).Where("branch_id = ?", id).Count(&terms).Error; err != nil {
+		return 0, err
+	}
+	return children + terms, nil
+}
+
+// Delete detaches the branch's villages and removes it.
+func (r *BranchRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Village{}).Where("branch_id = ?", id).
+			Update("branch_id", nil).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&model.Branch{}, id).Error
+	})
+}
+
+func (r *BranchRepository) GetPositions() ([]model.Position, error) {
+	var positions []model.Position
+	err := r.db.Order("sort_order ASC, name ASC").Find(&positions).Error
+	return positions, err
+}
+
+func (r *BranchRepository) GetPositionByID(id uint) (*model.Position, error) {
+	var position model.Position
+	err := r.db.First(&position, id).Error
+	return &position, err
+}
+
+func (r *BranchRepository) SavePosition(position *model.Position) error {
+	return r.db.Save(position).Error
+}
+
+func (r *BranchRepository) PositionInUse(id uint) bool {
+	var count int64
+	r.db.Model(&model.OfficerTerm{}).Where("position_id = ?", id).Count(&count)
+	return count > 0
+}
+
+func (r *BranchRepository) DeletePosition(id uint) error {
+	return r.db.Delete(&model.Position{}, id).Error
+}
+
+// GetTerms lists every officer term of the branch, ordered by position and
+// most recent start first.
+func (r *BranchRepository) GetTerms(branchID uint) ([]model.OfficerTerm, error) {
+	var terms []model.OfficerTerm
+	err := r.db.Preload("Position").Preload("User").
+		Joins("JOIN positions ON positions.id = officer_terms.position_id").
+		Where("officer_terms.branch_id = ?", branchID).
+		Order("positions.sort_order ASC, officer_terms.start_date DESC").
+		Find(&terms).Error
+	return terms, err
+}
+
+func (r *BranchRepository) GetTermByID(id uint) (*model.OfficerTerm, error) {
+	var term model.OfficerTerm
+	err := r.db.Preload("Position").Preload("User").First(&term, id).Error
+	return &term, err
+}
+
+// HasOverlappingTerm reports whether another term for the same position in
+// the branch overlaps [start, end]. A nil end runs indefinitely.
+func (r *BranchRepository) HasOverlappingTerm(branchID, positionID uint, start time.Time, end *time.Time, excludeID uint) (bool, error) {
+	var count int64
+	query := r.db.Model(&model.OfficerTerm{}).
+		Where("branch_id = ? AND position_id = ? AND id <> ?", branchID, positionID, excludeID).
+		Where("end_date IS NULL OR end_date >= ?", start)
+	if end != nil {
+		query = query.Where("start_date <= ?", *end)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+func (r *BranchRepository) SaveTerm(term *model.OfficerTerm) error {
+	return r.db.Omit(clause.Associations).Save(term).Error
+}
+
+func (r *BranchRepository) DeleteTerm(id uint) error {
+	return r.db.Delete(&model.OfficerTerm{}, id).Error
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/database"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CardRenewalRepository struct {
+	db *gorm.DB
+}
+
+func NewCardRenewalRepository() *CardRenewalRepository {
+	return &CardRenewalRepository{
+		db: database.DB,
+	}
+}
+
+func (r *CardRenewalRepository) GetAll(limit, offset int, status string) ([]model.CardRenewal, int64, error) {
+	var renewals []model.CardRenewal
+	var total int64
+
+	query := r.db.Model(&model.CardRenewal{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Preload("User").
+		Order("created_at ASC").
+		Limit(limit).Offset(offset).Find(&renewals).Error
+
+	return renewals, total, err
+}
+
+func (r *CardRenewalRepository) GetByID(id uint) (*model.CardRenewal, error) {
+	var renewal model.CardRenewal
+	err := r.db.Preload("User").First(&renewal, id).Error
+	return &renewal, err
+}
+
+func (r *CardRenewalRepository) GetByUserID(userID string) ([]model.CardRenewal, error) {
+	var renewals []model.CardRenewal
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").Find(&renewals).Error
+	return renewals, err
+}
+
+func (r *CardRenewalRepository) HasPending(userID string) bool {
+	var count int64
+	r.db.Model(&model.CardRenewal{}).
+		Where("user_id = ? AND status = ?", userID, model.ChangeRequestPending).
+		Count(&count)
+	return count > 0
+}
+
+func (r *CardRenewalRepository) Create(renewal *model.CardRenewal) error {
+	return r.db.Create(renewal).Error
+}
+
+// Approve moves the member's card back to approved for reprinting, records
+// the card history and closes the renewal in one transaction.
+func (r *CardRenewalRepository) Approve(renewal *model.CardRenewal, fromStatus, reviewerID string, note *string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).
+			Where("id = ? AND card_status = ?", renewal.UserID, fromStatus).
+			Update("card_status", model.CardStatusApproved)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("card status of %s changed during the update", renewal.UserID)
+		}
+
+		renewalNote := "card renewal approved"
+		err := tx.Create(&model.CardStatusHistory{
+			UserID:     renewal.UserID,
+			FromStatus: fromStatus,
+			ToStatus:   model.CardStatusApproved,
+			ChangedBy:  &reviewerID,
+			Note:       &renewalNote,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return r.review(tx, renewal.ID, model.ChangeRequestApproved, reviewerID, note)
+	})
+}
+
+func (r *CardRenewalRepository) Reject(id uint, reviewerID string, note *string) error {
+	return r.review(r.db, id, model.ChangeRequestRejected, reviewerID, note)
+}
+
+func (r *CardRenewalRepository) review(tx *gorm.DB, id uint, status, reviewerID string, note *string) error {
+	now := time.Now()
+	return tx.Model(&model.CardRenewal{}).Where("id = ?", id).
+		Updates(&model.CardRenewal{
+			Status:     status,
+			ReviewedBy: &reviewerID,
+			ReviewNote: note,
+			ReviewedAt: &now,
+		}).Error
+}
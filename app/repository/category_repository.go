@@ -3,6 +3,7 @@ package repository
 import (
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/database"
+	"time"
 	"gorm.io/gorm"
 )
 
@@ -36,6 +37,32 @@ func (r *CategoryRepository) GetAll(limit, offset int, activeOnly bool) ([]model
 	return categories, total, err
 }
 
+func (r *CategoryRepository) GetByID(id uint) (*model.Category, error) {
+	var category model.Category
+	err := r.db.First(&category, id).Error
+	return &category, err
+}
+
 func (r *CategoryRepository) Create(category *model.Category) error {
 	return r.db.Create(category).Error
-}
\ No newline at end of file
+}
+
+func (r *CategoryRepository) UpdateSLA(id uint, columns map[string]interface{}) error {
+	return r.db.Model(&model.Category{}).Where("id = ?", id).Updates(columns).Error
+}
+
+func (r *CategoryRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Category{}, id).Error
+}
+
+func (r *CategoryRepository) GetDeleted(limit, offset int) ([]model.Category, int64, error) {
+	return getDeleted[model.Category](r.db, limit, offset)
+}
+
+func (r *CategoryRepository) Restore(id uint) error {
+	return restore[model.Category](r.db, id)
+}
+
+func (r *CategoryRepository) PurgeDeleted(before time.Time) (int64, error) {
+	return purgeDeleted[model.Category](r.db, before)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/database"
+	"time"
 	"gorm.io/gorm"
 )
 
@@ -64,4 +65,16 @@ func (r *DocumentRepository) Update(id uint, document *model.Document) error {
 
 func (r *DocumentRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Document{}, id).Error
-}
\ No newline at end of file
+}
+
+func (r *DocumentRepository) GetDeleted(limit, offset int) ([]model.Document, int64, error) {
+	return getDeleted[model.Document](r.db, limit, offset)
+}
+
+func (r *DocumentRepository) Restore(id uint) error {
+	return restore[model.Document](r.db, id)
+}
+
+func (r *DocumentRepository) PurgeDeleted(before time.Time) (int64, error) {
+	return purgeDeleted[model.Document](r.db, before)
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/database"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DuesRepository struct {
+	db *gorm.DB
+}
+
+func NewDuesRepository() *DuesRepository {
+	return &DuesRepository{
+		db: database.DB,
+	}
+}
+
+func (r *DuesRepository) GetSchedules(activeOnly bool) ([]model.DuesSchedule, error) {
+	var schedules []model.DuesSchedule
+	query := r.db.Preload("Village")
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Order("start_date DESC").Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *DuesRepository) GetScheduleByID(id uint) (*model.DuesSchedule, error) {
+	var schedule model.DuesSchedule
+	err := r.db.Preload("Village").First(&schedule, id).Error
+	return &schedule, err
+}
+
+// GetScheduleForVillage returns the active schedule in force at now for a
+// village, falling back to the schedule that applies to all villages.
+// Schedules starting later or already ended are ignored, the same rule as
+// DuesSchedule.AppliesTo.
+func (r *DuesRepository) GetScheduleForVillage(villageID *uint, now time.Time) (*model.DuesSchedule, error) {
+	var schedule model.DuesSchedule
+	today := now.Format("2006-01-02")
+	query := r.db.Where("is_active = ? AND start_date <= ? AND (end_date IS NULL OR end_date >= ?)",
+		true, today, today)
+	if villageID != nil {
+		query = query.Where("village_id = ? OR village_id IS NULL", *villageID)
+	} else {
+		query = query.Where("village_id IS NULL")
+	}
+	// Village-specific schedules sort before the global one
+	err := query.Order("village_id IS NULL ASC, start_date DESC").First(&schedule).Error
+	return &schedule, err
+}
+
+func (r *DuesRepository) CreateSchedule(schedule *model.DuesSchedule) error {
+	return r.db.Create(schedule).Error
+}
+
+func (r *DuesRepository) UpdateSchedule(id uint, schedule *model.DuesSchedule) error {
+	return r.db.Where("id = ?", id).Updates(schedule).Error
+}
+
+func (r *DuesRepository) DeactivateSchedule(id uint) error {
+	return r.db.Model(&model.DuesSchedule{}).Where("id = ?", id).Update("is_active", false).Error
+}
+
+func (r *DuesRepository) CountPayments(scheduleID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.DuesPayment{}).Where("schedule_id = ?", scheduleID).Count(&count).Error
+	return count, err
+}
+
+func (r *DuesRepository) DeleteSchedule(id uint) error {
+	return r.db.Delete(&model.DuesSchedule{}, id).Error
+}
+
+func (r *DuesRepository) GetPayments(limit, offset int, userID string, villageID *uint, from, to *time.Time) ([]model.DuesPayment, int64, error) {
+	var payments []model.DuesPayment
+	var total int64
+
+	query := r.db.Model(&model.DuesPayment{})
+	if userID != "" {
+		query = query.Where("dues_payments.user_id = ?", userID)
+	}
+	if villageID != nil {
+		query = query.Joins("JOIN users ON users.id = dues_payments.user_id").
+			Where("users.village_id = ?", *villageID)
+	}
+	if from != nil {
+		query = query.Where("dues_payments.paid_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("dues_payments.paid_at < ?", *to)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Preload("User").Preload("Schedule").
+		Order("dues_payments.paid_at DESC").
+		Limit(limit).Offset(offset).Find(&payments).Error
+
+	return payments, total, err
+}
+
+func (r *DuesRepository) CreatePayment(payment *model.DuesPayment) error {
+	return r.db.Create(payment).Error
+}
+
+func (r *DuesRepository) GetPaidPeriods(userID string, scheduleID uint) (int, error) {
+	var periods int
+	err := r.db.Model(&model.DuesPayment{}).
+		Select("COALESCE(SUM(periods), 0)").
+		Where("user_id = ? AND schedule_id = ?", userID, scheduleID).
+		Scan(&periods).Error
+	return periods, err
+}
+
+func (r *DuesRepository) GetVillageSummary(from, to time.Time) ([]model.VillageDuesSummary, error) {
+	var summary []model.VillageDuesSummary
+
+	query := `
+		SELECT v.id as village_id, v.name as village_name,
+			   COALESCE(p.total_amount, 0) as total_amount,
+			   COALESCE(p.total_payments, 0) as total_payments,
+			   COALESCE(p.paying_members, 0) as paying_members,
+			   COALESCE(m.total_members, 0) as total_members
+		FROM villages v
+		LEFT JOIN (
+			SELECT u.village_id, SUM(dp.amount) as total_amount,
+				   COUNT(*) as total_payments,
+				   COUNT(DISTINCT dp.user_id) as paying_members
+			FROM dues_payments dp
+			JOIN users u ON u.id = dp.user_id
+			WHERE dp.paid_at >= ? AND dp.paid_at < ?
+			GROUP BY u.village_id
+		) p ON v.id = p.village_id
+		LEFT JOIN (
+			SELECT village_id, COUNT(*) as total_members
+			FROM users
+			WHERE deleted_at IS NULL
+			GROUP BY village_id
+		) m ON v.id = m.village_id
+		WHERE v.deleted_at IS NULL
+		ORDER BY v.name ASC
+	`
+
+	err := r.db.Raw(query, from, to).Scan(&summary).Error
+	return summary, err
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/database"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ErasureRepository struct {
+	db *gorm.DB
+}
+
+func NewErasureRepository() *ErasureRepository {
+	return &ErasureRepository{
+		db: database.DB,
+	}
+}
+
+func (r *ErasureRepository) GetAll(limit, offset int, status string) ([]model.ErasureRequest, int64, error) {
+	var requests []model.ErasureRequest
+	var total int64
+
+	query := r.db.Model(&model.ErasureRequest{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Preload("User").
+		Order("created_at ASC").
+		Limit(limit).Offset(offset).Find(&requests).Error
+
+	return requests, total, err
+}
+
+func (r *ErasureRepository) GetByID(id uint) (*model.ErasureRequest, error) {
+	var request model.ErasureRequest
+	err := r.db.Preload("User").First(&request, id).Error
+	return &request, err
+}
+
+func (r *ErasureRepository) GetByUserID(userID string) ([]model.ErasureRequest, error) {
+	var requests []model.ErasureRequest
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+func (r *ErasureRepository) HasPending(userID string) bool {
+	var count int64
+	r.db.Model(&model.ErasureRequest{}).
+		Where("user_id = ? AND status = ?", userID, model.ChangeRequestPending).
+		Count(&count)
+	return count > 0
+}
+
+func (r *ErasureRepository) Create(request *model.ErasureRequest) error {
+	return r.db.Create(request).Error
+}
+
+// Approve anonymizes the member and closes the request in one transaction.
+// Village, gender, branch, card status and birth year are kept for the
+// statistics; everything that identifies the person is cleared. It returns
+// the paths of the deleted documents so the caller can remove the files
+// once the transaction has committed.
+func (r *ErasureRepository) Approve(request *model.ErasureRequest, passwordHash, reviewerID string, note *string) ([]string, error) {
+	var filePaths []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.First(&user, "id = ?", request.UserID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var birthYear interface{}
+		if user.BirthDate != nil {
+			birthYear = time.Date(user.BirthDate.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		}
+
+		err := tx.Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"name":               "Anggota Terhapus",
+			"password":           passwordHash,
+			"telp":               nil,
+			"nik":                nil,
+			"address":            nil,
+			"birth_place":        nil,
+			"birth_date":         birthYear,
+			"occupation":         nil,
+			"education":          nil,
+			"marital_status":     nil,
+			"household_id":       nil,
+			"household_relation": nil,
+			"photo_url":          nil,
+			"photo_thumb_url":    nil,
+			"referral_code":      nil,
+			"suspended_reason":   nil,
+			"anonymized_at":      now,
+			"is_active":          false,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// A household headed by the member is identified by their KK, so
+		// its number and address go too. The number stays unique.
+		if user.HouseholdID != nil && user.HouseholdRelation != nil &&
+			*user.HouseholdRelation == model.HouseholdRelationHead {
+			err = tx.Model(&model.Household{}).Where("id = ?", *user.HouseholdID).
+				Updates(map[string]interface{}{
+					"kk_number": fmt.Sprintf("TERHAPUS-%d", *user.HouseholdID),
+					"address":   nil,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// Requests and records the member left behind keep their dates and
+		// outcomes, but not what was written in them
+		cleared := []struct {
+			model   interface{}
+			columns map[string]interface{}
+		}{
+			{&model.ProfileChangeRequest{}, map[string]interface{}{
+				"nik": nil, "village_id": nil, "reason": nil, "review_note": nil}},
+			{&model.CardRenewal{}, map[string]interface{}{"note": nil, "review_note": nil}},
+			{&model.CardStatusHistory{}, map[string]interface{}{"note": nil}},
+			{&model.ErasureRequest{}, map[string]interface{}{"reason": nil, "review_note": nil}},
+			{&model.DuesPayment{}, map[string]interface{}{"note": nil}},
+		}
+		for _, c := range cleared {
+			if err := tx.Model(c.model).Where("user_id = ?", user.ID).Updates(c.columns).Error; err != nil {
+				return err
+			}
+		}
+
+		// Notifications quote ticket titles and other personal details
+		if err := tx.Where("user_id = ?", user.ID).Delete(&model.Notification{}).Error; err != nil {
+			return err
+		}
+
+		// Uploaded documents are personal files, not statistics. They are
+		// deleted for good so they cannot be restored from the trash.
+		err = tx.Unscoped().Model(&model.Document{}).Where("user_id = ?", user.ID).
+			Pluck("file_path", &filePaths).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&model.Document{}).Error; err != nil {
+			return err
+		}
+
+		// Ticket text is free-form personal data. The tickets stay for the
+		// statistics, but their text and every message and note on them
+		// are cleared, as are messages the member wrote elsewhere.
+		ticketIDs := tx.Unscoped().Model(&model.Ticket{}).Select("id").Where("user_id = ?", user.ID)
+		err = tx.Unscoped().Model(&model.Ticket{}).Where("user_id = ?", user.ID).
+			Updates(map[string]interface{}{
+				"title":       "Tiket Terhapus",
+				"description": "",
+				"resolution":  nil,
+			}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&model.TicketMessage{}).
+			Where("ticket_id IN (?) OR user_id = ?", ticketIDs, user.ID).
+			Update("body", "").Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&model.TicketStatusHistory{}).Where("ticket_id IN (?)", ticketIDs).
+			Update("note", nil).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&model.ErasureRequest{}).Where("id = ?", request.ID).
+			Updates(&model.ErasureRequest{
+				Status:     model.ChangeRequestApproved,
+				ReviewedBy: &reviewerID,
+				ReviewNote: note,
+				ReviewedAt: &now,
+			}).Error
+	})
+	return filePaths, err
+}
+
+func (r *ErasureRepository) Reject(id uint, reviewerID string, note *string) error {
+	now := time.Now()
+	return r.db.Model(&model.ErasureRequest{}).Where("id = ?", id).
+		Updates(&model.ErasureRequest{
+			Status:     model.ChangeRequestRejected,
+			ReviewedBy: &reviewerID,
+			ReviewNote: note,
+			ReviewedAt: &now,
+		}).Error
+}
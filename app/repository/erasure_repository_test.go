@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/database"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB connects to the Postgres database named by TEST_DATABASE_URL,
+// migrates it and returns a transaction that is rolled back after the test.
+func openTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	database.DB = db
+	database.Migrate()
+
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+	database.DB = tx
+	return tx
+}
+
+func TestErasureApproveClearsPersonalData(t *testing.T) {
+	tx := openTestDB(t)
+
+	suffix := time.Now().UnixNano()
+	nik := fmt.Sprintf("9%015d", suffix%1e15)
+	telp := fmt.Sprintf("08%010d", suffix%1e10)
+	address := fmt.Sprintf("Jl. Erasure Test %d", suffix)
+	secrets := []string{nik, telp, address}
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	household := &model.Household{KKNumber: fmt.Sprintf("KK%d", suffix), Address: &address}
+	must(tx.Create(household).Error)
+
+	head := model.HouseholdRelationHead
+	reason := "suspended after calling " + telp
+	user := &model.User{
+		ID:                fmt.Sprintf("erasure-%d", suffix),
+		Name:              "Erasure Test",
+		Password:          "hash",
+		NIK:               &nik,
+		Telp:              &telp,
+		Address:           &address,
+		HouseholdID:       &household.ID,
+		HouseholdRelation: &head,
+		SuspendedReason:   &reason,
+	}
+	must(tx.Create(user).Error)
+
+	note := "reach me at " + telp + ", " + address
+	must(tx.Create(&model.ProfileChangeRequest{UserID: user.ID, NIK: &nik, Reason: &note}).Error)
+	must(tx.Create(&model.CardRenewal{UserID: user.ID, Note: &note}).Error)
+	must(tx.Create(&model.CardStatusHistory{UserID: user.ID, ToStatus: model.CardStatusPending, Note: &note}).Error)
+
+	schedule := &model.DuesSchedule{Name: "Erasure test", Amount: 10000, StartDate: time.Now()}
+	must(tx.Create(schedule).Error)
+	must(tx.Create(&model.DuesPayment{
+		UserID: user.ID, ScheduleID: schedule.ID, Amount: 10000, Method: model.PaymentMethodCash,
+		ReceiptNumber: fmt.Sprintf("ERASE-%d", suffix), PaidAt: time.Now(), Note: &note,
+	}).Error)
+
+	ticket := &model.Ticket{UserID: user.ID, Title: "NIK " + nik, Description: note, Resolution: &note}
+	must(tx.Create(ticket).Error)
+	must(tx.Create(&model.TicketMessage{TicketID: ticket.ID, UserID: user.ID, Body: note}).Error)
+	must(tx.Create(&model.Notification{UserID: user.ID, Type: "test", Title: "test", Message: note}).Error)
+
+	request := &model.ErasureRequest{UserID: user.ID, Reason: &note, Status: model.ChangeRequestPending}
+	must(tx.Create(request).Error)
+
+	if _, err := NewErasureRepository().Approve(request, "erased", "admin", nil); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+
+	// Search every text column in the schema, not just the ones Approve
+	// knows about, so new tables holding member data are caught as well
+	var columns []struct {
+		TableName  string
+		ColumnName string
+	}
+	must(tx.Raw(`SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND data_type IN ('text', 'character varying')`).
+		Scan(&columns).Error)
+
+	for _, column := range columns {
+		for _, secret := range secrets {
+			var count int64
+			query := fmt.Sprintf(`SELECT COUNT(*) FROM %q WHERE %q LIKE ?`, column.TableName, column.ColumnName)
+			must(tx.Raw(query, "%"+secret+"%").Scan(&count).Error)
+			if count > 0 {
+				t.Errorf("%s.%s still holds %q", column.TableName, column.ColumnName, secret)
+			}
+		}
+	}
+}
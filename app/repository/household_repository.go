@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/database"
+
+	"gorm.io/gorm"
+)
+
+type HouseholdRepository struct {
+	db *gorm.DB
+}
+
+func NewHouseholdRepository() *HouseholdRepository {
+	return &HouseholdRepository{
+		db: database.DB,
+	}
+}
+
+func (r *HouseholdRepository) GetAll(limit, offset int, villageID *uint) ([]model.Household, int64, error) {
+	var households []model.Household
+	var total int64
+
+	query := r.db.Model(&model.Household{})
+	if villageID != nil {
+		query = query.Where("village_id = ?", *villageID)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Preload("Village").Preload("Members").
+		Order("kk_number ASC").
+		Limit(limit).Offset(offset).Find(&households).Error
+
+	return households, total, err
+}
+
+func (r *HouseholdRepository) GetByID(id uint) (*model.Household, error) {
+	var household model.Household
+	err := r.db.Preload("Village").Preload("Members").
+		First(&household, id).Error
+	return &household, err
+}
+
+func (r *HouseholdRepository) GetByKKNumber(kkNumber string) (*model.Household, error) {
+	var household model.Household
+	err := r.db.Preload("Village").Preload("Members").
+		Where("kk_number = ?", kkNumber).First(&household).Error
+	return &household, err
+}
+
+func (r *HouseholdRepository) Create(household *model.Household) error {
+	return r.db.Create(household).Error
+}
+
+func (r *HouseholdRepository) Update(id uint, household *model.Household) error {
+	return r.db.Where("id = ?", id).Updates(household).Error
+}
+
+// Delete removes the household and detaches its members in one transaction.
+func (r *HouseholdRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.User{}).Where("household_id = ?", id).
+			Updates(map[string]interface{}{"household_id": nil, "household_relation": nil}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&model.Household{}, id).Error
+	})
+}
+
+func (r *HouseholdRepository) SetMember(id uint, userID, relation string) error {
+	return r.db.Model(&model.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"household_id": id, "household_relation": relation}).Error
+}
+
+func (r *HouseholdRepository) RemoveMember(id uint, userID string) error {
+	result := r.db.Model(&model.User{}).Where("id = ? AND household_id = ?", userID, id).
+		Updates(map[string]interface{}{"household_id": nil, "household_relation": nil})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *HouseholdRepository) CountHeads(id uint, excludeUserID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.User{}).
+		Where("household_id = ? AND household_relation = ? AND id <> ?", id, model.HouseholdRelationHead, excludeUserID).
+		Count(&count).Error
+	return count, err
+}
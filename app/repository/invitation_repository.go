@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/database"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository() *InvitationRepository {
+	return &InvitationRepository{
+		db: database.DB,
+	}
+}
+
+// GetPending lists invitations that can still be accepted, optionally for
+// members of one village.
+func (r *InvitationRepository) GetPending(limit, offset int, villageID *uint, now time.Time) ([]model.Invitation, int64, error) {
+	var invitations []model.Invitation
+	var total int64
+
+	query := r.db.Model(&model.Invitation{}).
+		Where("invitations.accepted_at IS NULL AND invitations.revoked_at IS NULL AND invitations.expires_at > ?", now)
+	if villageID != nil {
+		query = query.Joins("JOIN users ON users.id = invitations.user_id").
+			Where("users.village_id = ?", *villageID)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Preload("User.Village").
+		Order("invitations.expires_at ASC").
+		Limit(limit).Offset(offset).Find(&invitations).Error
+
+	return invitations, total, err
+}
+
+func (r *InvitationRepository) GetByID(id uint) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := r.db.Preload("User").First(&invitation, id).Error
+	return &invitation, err
+}
+
+func (r *InvitationRepository) GetByTokenHash(hash string) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := r.db.Preload("User").Where("token_hash = ?", hash).First(&invitation).Error
+	return &invitation, err
+}
+
+// GetOpenByUserID returns the member's invitation that has been neither
+// accepted nor revoked, expired or not.
+func (r *InvitationRepository) GetOpenByUserID(userID string) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := r.db.Where("user_id = ? AND accepted_at IS NULL AND revoked_at IS NULL", userID).
+		Order("created_at DESC").First(&invitation).Error
+	return &invitation, err
+}
+
+func (r *InvitationRepository) Create(invitation *model.Invitation) error {
+	return r.db.Create(invitation).Error
+}
+
+// Resend replaces the token and expiry, which invalidates the link sent
+// before.
+func (r *InvitationRepository) Resend(id uint, tokenHash string, sentAt, expiresAt time.Time) error {
+	return r.db.Model(&model.Invitation{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"token_hash":   tokenHash,
+			"expires_at":   expiresAt,
+			"last_sent_at": sentAt,
+			"send_count":   gorm.Expr("send_count + 1"),
+		}).Error
+}
+
+func (r *InvitationRepository) Revoke(id uint, now time.Time) error {
+	return r.db.Model(&model.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("revoked_at", now).Error
+}
+
+// Accept marks the invitation used and sets the member's password in one
+// transaction. An invitation accepted, revoked or expired in the meantime
+// leaves the account untouched.
+func (r *InvitationRepository) Accept(invitation *model.Invitation, passwordHash string, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", invitation.ID, now).
+			Update("accepted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("invitation is no longer valid")
+		}
+
+		return tx.Model(&model.User{}).Where("id = ?", invitation.UserID).
+			Update("password", passwordHash).Error
+	})
+}
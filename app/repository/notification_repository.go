@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/database"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{
+		db: database.DB,
+	}
+}
+
+func (r *NotificationRepository) GetByUserID(userID string, limit, offset int) ([]model.Notification, int64, error) {
+	var notifications []model.Notification
+	var total int64
+
+	err := r.db.Model(&model.Notification{}).Where("user_id = ?", userID).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).Find(&notifications).Error
+
+	return notifications, total, err
+}
+
+func (r *NotificationRepository) Create(notification *model.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+func (r *NotificationRepository) MarkRead(id uint, userID string) error {
+	result := r.db.Model(&model.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
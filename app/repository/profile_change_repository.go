@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/database"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProfileChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewProfileChangeRepository() *ProfileChangeRepository {
+	return &ProfileChangeRepository{
+		db: database.DB,
+	}
+}
+
+func (r *ProfileChangeRepository) GetAll(limit, offset int, status string) ([]model.ProfileChangeRequest, int64, error) {
+	var requests []model.ProfileChangeRequest
+	var total int64
+
+	query := r.db.Model(&model.ProfileChangeRequest{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Preload("User").Preload("Village").
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).Find(&requests).Error
+
+	return requests, total, err
+}
+
+func (r *ProfileChangeRepository) GetByID(id uint) (*model.ProfileChangeRequest, error) {
+	var request model.ProfileChangeRequest
+	err := r.db.Preload("User").Preload("Village").First(&request, id).Error
+	return &request, err
+}
+
+func (r *ProfileChangeRepository) GetByUserID(userID string) ([]model.ProfileChangeRequest, error) {
+	var requests []model.ProfileChangeRequest
+	err := r.db.Preload("Village").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+func (r *ProfileChangeRepository) Create(request *model.ProfileChangeRequest) error {
+	return r.db.Create(request).Error
+}
+
+// Approve applies the requested fields to the user and marks the request
+// approved in one transaction.
+func (r *ProfileChangeRepository) Approve(request *model.ProfileChangeRequest, reviewerID string, note *string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		changes := map[string]interface{}{}
+		if request.NIK != nil {
+			changes["nik"] = *request.NIK
+		}
+		if request.VillageID != nil {
+			changes["village_id"] = *request.VillageID
+		}
+		if len(changes) > 0 {
+			if err := tx.Model(&model.User{}).Where("id = ?", request.UserID).Updates(changes).Error; err != nil {
+				return err
+			}
+		}
+
+		return r.review(tx, request.ID, model.ChangeRequestApproved, reviewerID, note)
+	})
+}
+
+func (r *ProfileChangeRepository) Reject(id uint, reviewerID string, note *string) error {
+	return r.review(r.db, id, model.ChangeRequestRejected, reviewerID, note)
+}
+
+func (r *ProfileChangeRepository) review(tx *gorm.DB, id uint, status, reviewerID string, note *string) error {
+	now := time.Now()
+	return tx.Model(&model.ProfileChangeRequest{}).Where("id = ?", id).
+		Updates(&model.ProfileChangeRequest{
+			Status:     status,
+			ReviewedBy: &reviewerID,
+			ReviewNote: note,
+			ReviewedAt: &now,
+		}).Error
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/database"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type RegionRepository struct {
+	db *gorm.DB
+}
+
+func NewRegionRepository() *RegionRepository {
+	return &RegionRepository{
+		db: database.DB,
+	}
+}
+
+// regionVillageIDs is a subquery of the villages under the filter's most
+// specific region. Kemendagri codes nest by prefix, so regencies and
+// provinces match on the start of the district code.
+func regionVillageIDs(db *gorm.DB, filter *model.RegionFilter) *gorm.DB {
+	query := db.Model(&model.Village{}).Select("id")
+	if filter.DistrictCode != "" {
+		return query.Where("district_code = ?", filter.DistrictCode)
+	}
+	return query.Where("district_code LIKE ?", mostSpecificCode(filter)+".%")
+}
+
+func (r *RegionRepository) GetTree() ([]model.Province, error) {
+	var provinces []model.Province
+	err := r.db.
+		Preload("Regencies", func(db *gorm.DB) *gorm.DB { return db.Order("code ASC") }).
+		Preload("Regencies.Districts", func(db *gorm.DB) *gorm.DB { return db.Order("code ASC") }).
+		Preload("Regencies.Districts.Villages", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_active = ?", true).Order("name ASC")
+		}).
+		Order("code ASC").Find(&provinces).Error
+	return provinces, err
+}
+
+func (r *RegionRepository) GetProvinces() ([]model.Province, error) {
+	var provinces []model.Province
+	err := r.db.Order("code ASC").Find(&provinces).Error
+	return provinces, err
+}
+
+func (r *RegionRepository) GetRegencies(provinceCode string) ([]model.Regency, error) {
+	var regencies []model.Regency
+	query := r.db.Model(&model.Regency{})
+	if provinceCode != "" {
+		query = query.Where("province_code = ?", provinceCode)
+	}
+	err := query.Order("code ASC").Find(&regencies).Error
+	return regencies, err
+}
+
+func (r *RegionRepository) GetDistricts(regencyCode string) ([]model.District, error) {
+	var districts []model.District
+	query := r.db.Model(&model.District{})
+	if regencyCode != "" {
+		query = query.Where("regency_code = ?", regencyCode)
+	}
+	err := query.Order("code ASC").Find(&districts).Error
+	return districts, err
+}
+
+func (r *RegionRepository) GetProvince(code string) (*model.Province, error) {
+	var province model.Province
+	err := r.db.First(&province, "code = ?", code).Error
+	return &province, err
+}
+
+func (r *RegionRepository) GetRegency(code string) (*model.Regency, error) {
+	var regency model.Regency
+	err := r.db.Preload("Province").First(&regency, "code = ?", code).Error
+	return &regency, err
+}
+
+func (r *RegionRepository) GetDistrict(code string) (*model.District, error) {
+	var district model.District
+	err := r.db.Preload("Regency.Province").First(&district, "code = ?", code).Error
+	return &district, err
+}
+
+func (r *RegionRepository) Create(region interface{}) error {
+	return r.db.Create(region).Error
+}
+
+// Rename updates the name, and for regencies the type, of a region. Codes
+// are fixed once created since children are keyed on them.
+func (r *RegionRepository) Rename(region interface{}, code string, columns map[string]interface{}) error {
+	return r.db.Model(region).Where("code = ?", code).Updates(columns).Error
+}
+
+// CountChildren reports how many regencies, districts or villages sit
+// directly under the region, so it is not deleted while still in use.
+// Trashed villages count too, since their district_code still references
+// the district.
+func (r *RegionRepository) CountChildren(level, code string) (int64, error) {
+	var count int64
+	var query *gorm.DB
+	switch level {
+	case model.RegionLevelProvince:
+		query = r.db.Model(&model.Regency{}).Where("province_code = ?", code)
+	case model.RegionLevelRegency:
+		query = r.db.Model(&model.District{}).Where("regency_code = ?", code)
+	default:
+		query = r.db.Unscoped().Model(&model.Village{}).Where("district_code = ?", code)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func (r *RegionRepository) Delete(region interface{}, code string) error {
+	return r.db.Where("code = ?", code).Delete(region).Error
+}
+
+// regionLevels maps each level to its table and the length of its code,
+// which is also the length of the district code prefix it owns.
+var regionLevels = map[string]struct {
+	table   string
+	codeLen int
+}{
+	model.RegionLevelProvince: {"provinces", 2},
+	model.RegionLevelRegency:  {"regencies", 5},
+	model.RegionLevelDistrict: {"districts", 8},
+}
+
+// Summary counts villages, members and tickets for every region at the
+// given level, optionally limited to the regions under filter.
+func (r *RegionRepository) Summary(level string, filter *model.RegionFilter) ([]model.RegionSummary, error) {
+	var summaries []model.RegionSummary
+
+	if level == model.RegionLevelVillage {
+		query := r.db.Table("villages AS r").
+			Select(`r.code, r.name, 1 AS total_villages,
+				COUNT(u.id) AS total_members,
+				COUNT(u.id) FILTER (WHERE u.card_status IN ?) AS total_approved,
+				(SELECT COUNT(*) FROM tickets t JOIN users tu ON tu.id = t.user_id
+					WHERE tu.village_id = r.id AND t.deleted_at IS NULL AND tu.deleted_at IS NULL) AS total_tickets`,
+				model.ApprovedCardStatuses).
+			Joins("LEFT JOIN users u ON u.village_id = r.id AND u.deleted_at IS NULL").
+			Where("r.deleted_at IS NULL")
+		if !filter.IsEmpty() {
+			query = query.Where("r.id IN (?)", regionVillageIDs(r.db, filter))
+		}
+		err := query.Group("r.id, r.code, r.name").Order("r.name ASC").Scan(&summaries).Error
+		return summaries, err
+	}
+
+	info, ok := regionLevels[level]
+	if !ok {
+		return nil, fmt.Errorf("invalid region level %q", level)
+	}
+
+	prefix := fmt.Sprintf("LEFT(%%s, %d)", info.codeLen)
+	query := r.db.Table(info.table+" AS r").
+		Select(`r.code, r.name,
+			COUNT(DISTINCT v.id) AS total_villages,
+			COUNT(u.id) AS total_members,
+			COUNT(u.id) FILTER (WHERE u.card_status IN ?) AS total_approved,
+			(SELECT COUNT(*) FROM tickets t
+				JOIN users tu ON tu.id = t.user_id
+				JOIN villages tv ON tv.id = tu.village_id
+				WHERE `+fmt.Sprintf(prefix, "tv.district_code")+` = r.code
+					AND t.deleted_at IS NULL AND tu.deleted_at IS NULL AND tv.deleted_at IS NULL) AS total_tickets`,
+			model.ApprovedCardStatuses).
+		Joins("LEFT JOIN villages v ON " + fmt.Sprintf(prefix, "v.district_code") + " = r.code AND v.deleted_at IS NULL").
+		Joins("LEFT JOIN users u ON u.village_id = v.id AND u.deleted_at IS NULL")
+
+	// Keep the filtered region, the regions under it and the ones above it
+	if code := mostSpecificCode(filter); code != "" {
+		query = query.Where("r.code = ? OR r.code LIKE ? OR ? LIKE r.code || '.%'", code, code+".%", code)
+	}
+
+	err := query.Group("r.code, r.name").Order("r.code ASC").Scan(&summaries).Error
+	return summaries, err
+}
+
+func mostSpecificCode(filter *model.RegionFilter) string {
+	switch {
+	case filter.IsEmpty():
+		return ""
+	case filter.DistrictCode != "":
+		return filter.DistrictCode
+	case filter.RegencyCode != "":
+		return filter.RegencyCode
+	default:
+		return filter.ProvinceCode
+	}
+}
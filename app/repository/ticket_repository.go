@@ -3,8 +3,11 @@ package repository
 import (
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/database"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TicketRepository struct {
@@ -17,11 +20,11 @@ func NewTicketRepository() *TicketRepository {
 	}
 }
 
-func (r *TicketRepository) GetAll(limit, offset int, status *model.TicketStatus) ([]model.Ticket, int64, error) {
+func (r *TicketRepository) GetAll(limit, offset int, status *model.TicketStatus, region *model.RegionFilter) ([]model.Ticket, int64, error) {
 	var tickets []model.Ticket
 	var total int64
 
-	query := r.db.Model(&model.Ticket{})
+	query := r.applyRegion(r.db.Model(&model.Ticket{}), region)
 	if status != nil {
 		query = query.Where("status = ?", *status)
 	}
@@ -31,16 +34,25 @@ func (r *TicketRepository) GetAll(limit, offset int, status *model.TicketStatus)
 		return nil, 0, err
 	}
 
-	err = query.Preload("User").Preload("Category").
+	err = query.Preload("User").Preload("Category").Preload("Assignee").
 		Order("created_at DESC").
 		Limit(limit).Offset(offset).Find(&tickets).Error
 
 	return tickets, total, err
 }
 
+// applyRegion keeps tickets raised by members living under the region.
+func (r *TicketRepository) applyRegion(query *gorm.DB, region *model.RegionFilter) *gorm.DB {
+	if region.IsEmpty() {
+		return query
+	}
+	return query.Where("user_id IN (?)",
+		r.db.Model(&model.User{}).Select("id").Where("village_id IN (?)", regionVillageIDs(r.db, region)))
+}
+
 func (r *TicketRepository) GetByID(id uint) (*model.Ticket, error) {
 	var ticket model.Ticket
-	err := r.db.Preload("User").Preload("Category").
+	err := r.db.Preload("User").Preload("Category").Preload("Assignee").
 		First(&ticket, id).Error
 	return &ticket, err
 }
@@ -62,6 +74,28 @@ func (r *TicketRepository) GetByUserID(userID string, limit, offset int) ([]mode
 	return tickets, total, err
 }
 
+// GetByAssignee lists the tickets assigned to a staff member, open ones
+// first.
+func (r *TicketRepository) GetByAssignee(assigneeID string, limit, offset int, status *model.TicketStatus) ([]model.Ticket, int64, error) {
+	var tickets []model.Ticket
+	var total int64
+
+	query := r.db.Model(&model.Ticket{}).Where("assignee_id = ?", assigneeID)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("User").Preload("Category").
+		Order("status IN ('resolved', 'closed') ASC, created_at ASC").
+		Limit(limit).Offset(offset).Find(&tickets).Error
+
+	return tickets, total, err
+}
+
 func (r *TicketRepository) Create(ticket *model.Ticket) error {
 	return r.db.Create(ticket).Error
 }
@@ -74,13 +108,116 @@ func (r *TicketRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Ticket{}, id).Error
 }
 
-func (r *TicketRepository) GetCountByStatus() (map[model.TicketStatus]int64, error) {
+// GetMessages returns the thread oldest first. Internal notes are left out
+// unless includeInternal is set.
+func (r *TicketRepository) GetMessages(ticketID uint, includeInternal bool) ([]model.TicketMessage, error) {
+	var messages []model.TicketMessage
+	query := r.db.Preload("User").Where("ticket_id = ?", ticketID)
+	if !includeInternal {
+		query = query.Where("is_internal = ?", false)
+	}
+	err := query.Order("created_at ASC").Find(&messages).Error
+	return messages, err
+}
+
+// GetMessagesForExport returns what a member can see of the threads on
+// their tickets, plus any message they wrote elsewhere.
+func (r *TicketRepository) GetMessagesForExport(userID string) ([]model.TicketMessage, error) {
+	var messages []model.TicketMessage
+	ticketIDs := r.db.Unscoped().Model(&model.Ticket{}).Select("id").Where("user_id = ?", userID)
+	err := r.db.Where("(ticket_id IN (?) AND is_internal = ?) OR user_id = ?", ticketIDs, false, userID).
+		Order("ticket_id ASC, created_at ASC").Find(&messages).Error
+	return messages, err
+}
+
+// CreateMessage stores the message and, when change is not nil, applies
+// the status change in the same transaction.
+func (r *TicketRepository) CreateMessage(message *model.TicketMessage, change *model.TicketStatusHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		if message.FromStaff && !message.IsInternal {
+			err := tx.Model(&model.Ticket{}).
+				Where("id = ? AND first_responded_at IS NULL", message.TicketID).
+				Update("first_responded_at", message.CreatedAt).Error
+			if err != nil {
+				return err
+			}
+		}
+		if change == nil {
+			return nil
+		}
+		return changeTicketStatus(tx, change, message.FromStaff, nil)
+	})
+}
+
+// ChangeStatus moves the ticket and records the history row in one
+// transaction. Extra columns, such as the resolution, are set alongside.
+// staff tells whether the actor is staff rather than the reporter.
+func (r *TicketRepository) ChangeStatus(change *model.TicketStatusHistory, staff bool, extra map[string]interface{}) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return changeTicketStatus(tx, change, staff, extra)
+	})
+}
+
+// changeTicketStatus fails if the ticket's status moved since it was read.
+// ResolvedAt is stamped on resolve or close and cleared on reopen. Staff
+// taking up or resolving a ticket counts as its first response; merely
+// marking it read, or the reporter reopening it, does not.
+func changeTicketStatus(tx *gorm.DB, change *model.TicketStatusHistory, staff bool, extra map[string]interface{}) error {
+	columns := map[string]interface{}{"status": change.ToStatus}
+	if staff && (change.ToStatus == model.TicketStatusInProgress || change.ToStatus == model.TicketStatusResolved) {
+		columns["first_responded_at"] = gorm.Expr("COALESCE(first_responded_at, ?)", time.Now())
+	}
+	if change.ToStatus.IsFinished() {
+		if !change.FromStatus.IsFinished() {
+			columns["resolved_at"] = time.Now()
+		}
+	} else {
+		columns["resolved_at"] = nil
+	}
+	for column, value := range extra {
+		columns[column] = value
+	}
+
+	result := tx.Model(&model.Ticket{}).
+		Where("id = ? AND status = ?", change.TicketID, change.FromStatus).
+		Updates(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("status of ticket %d changed during the update", change.TicketID)
+	}
+
+	return tx.Create(change).Error
+}
+
+func (r *TicketRepository) GetStatusHistory(ticketID uint) ([]model.TicketStatusHistory, error) {
+	var history []model.TicketStatusHistory
+	err := r.db.Where("ticket_id = ?", ticketID).
+		Order("created_at ASC").Find(&history).Error
+	return history, err
+}
+
+// MarkThreadRead records that one side of the ticket has read the thread.
+func (r *TicketRepository) MarkThreadRead(ticketID uint, staff bool, readAt time.Time) error {
+	column := "reporter_read_at"
+	if staff {
+		column = "staff_read_at"
+	}
+	return r.db.Model(&model.Ticket{}).Where("id = ?", ticketID).
+		UpdateColumn(column, readAt).Error
+}
+
+func (r *TicketRepository) GetCountByStatus(region *model.RegionFilter) (map[model.TicketStatus]int64, error) {
 	var results []struct {
 		Status model.TicketStatus
 		Count  int64
 	}
 
-	err := r.db.Model(&model.Ticket{}).
+	err := r.applyRegion(r.db.Model(&model.Ticket{}), region).
 		Select("status, count(*) as count").
 		Group("status").
 		Scan(&results).Error
@@ -95,4 +232,247 @@ func (r *TicketRepository) GetCountByStatus() (map[model.TicketStatus]int64, err
 	}
 
 	return counts, nil
-}
\ No newline at end of file
+}
+
+// GetSLAStats measures first response and resolution against the due
+// dates. A target counts once it has been met or its due date has passed.
+func (r *TicketRepository) GetSLAStats(region *model.RegionFilter, now time.Time) (*model.TicketSLAStats, error) {
+	var result struct {
+		FirstResponseMeasured int64
+		FirstResponseMet      int64
+		ResolutionMeasured    int64
+		ResolutionMet         int64
+		OpenBreached          int64
+		Escalated             int64
+	}
+
+	err := r.applyRegion(r.db.Model(&model.Ticket{}), region).
+		Select(`COUNT(*) FILTER (WHERE first_response_due_at IS NOT NULL
+				AND (first_responded_at IS NOT NULL OR first_response_due_at < @now)) AS first_response_measured,
+			COUNT(*) FILTER (WHERE first_responded_at <= first_response_due_at) AS first_response_met,
+			COUNT(*) FILTER (WHERE resolution_due_at IS NOT NULL
+				AND (resolved_at IS NOT NULL OR resolution_due_at < @now)) AS resolution_measured,
+			COUNT(*) FILTER (WHERE resolved_at <= resolution_due_at) AS resolution_met,
+			COUNT(*) FILTER (WHERE status NOT IN @finished
+				AND (first_response_breached_at IS NOT NULL OR resolution_breached_at IS NOT NULL)) AS open_breached,
+			COUNT(escalated_at) AS escalated`,
+			map[string]interface{}{
+				"now":      now,
+				"finished": []model.TicketStatus{model.TicketStatusResolved, model.TicketStatusClosed},
+			}).
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TicketSLAStats{
+		FirstResponse: slaCompliance(result.FirstResponseMeasured, result.FirstResponseMet),
+		Resolution:    slaCompliance(result.ResolutionMeasured, result.ResolutionMet),
+		OpenBreached:  result.OpenBreached,
+		Escalated:     result.Escalated,
+	}, nil
+}
+
+func slaCompliance(measured, met int64) model.SLACompliance {
+	compliance := model.SLACompliance{Measured: measured, Met: met, Breached: measured - met}
+	if measured > 0 {
+		compliance.Percentage = float64(met) * 100 / float64(measured)
+	}
+	return compliance
+}
+
+// GetSLABreaches returns open tickets that missed a target not flagged
+// yet.
+func (r *TicketRepository) GetSLABreaches(now time.Time) ([]model.Ticket, error) {
+	var tickets []model.Ticket
+	err := r.db.Preload("Category").
+		Where("status NOT IN ?", []model.TicketStatus{model.TicketStatusResolved, model.TicketStatusClosed}).
+		Where(`(first_responded_at IS NULL AND first_response_due_at < ? AND first_response_breached_at IS NULL)
+			OR (resolution_due_at < ? AND resolution_breached_at IS NULL)`, now, now).
+		Find(&tickets).Error
+	return tickets, err
+}
+
+// MarkSLABreach stamps the breached targets and, when escalatedToID is
+// set, the escalation.
+func (r *TicketRepository) MarkSLABreach(ticketID uint, firstResponse, resolution bool, escalatedToID *string, at time.Time) error {
+	columns := map[string]interface{}{}
+	if firstResponse {
+		columns["first_response_breached_at"] = at
+	}
+	if resolution {
+		columns["resolution_breached_at"] = at
+	}
+	if escalatedToID != nil {
+		columns["escalated_at"] = at
+		columns["escalated_to_id"] = *escalatedToID
+	}
+	return r.db.Model(&model.Ticket{}).Where("id = ?", ticketID).Updates(columns).Error
+}
+
+func (r *TicketRepository) GetDeleted(limit, offset int) ([]model.Ticket, int64, error) {
+	return getDeleted[model.Ticket](r.db, limit, offset)
+}
+
+func (r *TicketRepository) Restore(id uint) error {
+	return restore[model.Ticket](r.db, id)
+}
+
+func (r *TicketRepository) PurgeDeleted(before time.Time) (int64, error) {
+	return purgeDeleted[model.Ticket](r.db, before)
+}
+
+// Assign sets or, with a nil assigneeID, clears the ticket's assignee.
+func (r *TicketRepository) Assign(ticketID uint, assigneeID, assignedByID *string) error {
+	columns := map[string]interface{}{
+		"assignee_id":    assigneeID,
+		"assigned_by_id": assignedByID,
+		"assigned_at":    nil,
+	}
+	if assigneeID != nil {
+		columns["assigned_at"] = time.Now()
+	}
+	return r.db.Model(&model.Ticket{}).Where("id = ?", ticketID).Updates(columns).Error
+}
+
+// GetWorkload counts open tickets per assignee. Tickets past their
+// resolution due date, or without one and created before overdueBefore,
+// also count as overdue.
+func (r *TicketRepository) GetWorkload(now, overdueBefore time.Time) ([]model.AssigneeWorkload, error) {
+	var workload []model.AssigneeWorkload
+	err := r.db.Table("tickets t").
+		Select(`t.assignee_id, u.name, COUNT(*) AS open,
+			COUNT(*) FILTER (WHERE t.resolution_due_at < ?
+				OR (t.resolution_due_at IS NULL AND t.created_at < ?)) AS overdue`, now, overdueBefore).
+		Joins("JOIN users u ON u.id = t.assignee_id").
+		Where("t.deleted_at IS NULL AND t.status NOT IN ?",
+			[]model.TicketStatus{model.TicketStatusResolved, model.TicketStatusClosed}).
+		Group("t.assignee_id, u.name").
+		Order("open DESC").
+		Scan(&workload).Error
+	return workload, err
+}
+
+func (r *TicketRepository) GetAssignmentRules() ([]model.TicketAssignmentRule, error) {
+	var rules []model.TicketAssignmentRule
+	err := r.db.Preload("Category").Preload("Village").Preload("Staff").
+		Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *TicketRepository) GetAssignmentRuleByID(id uint) (*model.TicketAssignmentRule, error) {
+	var rule model.TicketAssignmentRule
+	err := r.db.Preload("Category").Preload("Village").Preload("Staff").
+		First(&rule, id).Error
+	return &rule, err
+}
+
+// FindAssignmentRule returns the active rule for the reporter's village or,
+// failing that, for the ticket category. It returns nil when neither has
+// one.
+func (r *TicketRepository) FindAssignmentRule(categoryID, villageID *uint) (*model.TicketAssignmentRule, error) {
+	var rules []model.TicketAssignmentRule
+	query := r.db.Where("is_active = ?", true)
+	switch {
+	case villageID != nil && categoryID != nil:
+		query = query.Where("village_id = ? OR category_id = ?", *villageID, *categoryID)
+	case villageID != nil:
+		query = query.Where("village_id = ?", *villageID)
+	case categoryID != nil:
+		query = query.Where("category_id = ?", *categoryID)
+	default:
+		return nil, nil
+	}
+
+	if err := query.Order("village_id IS NULL").Limit(1).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return &rules[0], nil
+}
+
+// CountAssignmentRules counts other rules covering the same category or
+// village, used to keep one rule per scope.
+func (r *TicketRepository) CountAssignmentRules(categoryID, villageID *uint, excludeID uint) (int64, error) {
+	var count int64
+	query := r.db.Model(&model.TicketAssignmentRule{}).Where("id <> ?", excludeID)
+	if villageID != nil {
+		query = query.Where("village_id = ?", *villageID)
+	} else {
+		query = query.Where("category_id = ?", categoryID)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// SaveAssignmentRule creates or updates the rule and replaces its staff.
+func (r *TicketRepository) SaveAssignmentRule(rule *model.TicketAssignmentRule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Staff.*").Save(rule).Error; err != nil {
+			return err
+		}
+		// Create skips a false IsActive in favour of the column default
+		if !rule.IsActive {
+			if err := tx.Model(rule).Update("is_active", false).Error; err != nil {
+				return err
+			}
+		}
+		// Save only adds join rows, so drop staff that left the pool
+		return tx.Model(rule).Omit("Staff.*").Association("Staff").Replace(rule.Staff)
+	})
+}
+
+func (r *TicketRepository) DeleteAssignmentRule(rule *model.TicketAssignmentRule) error {
+	return r.db.Select("Staff").Delete(rule).Error
+}
+
+// AssignByRule gives the ticket to the staff member after the rule's last
+// assignee, ordered by ID. IDs are sorted bytewise so the SQL order matches
+// the Go string comparison used to find the next one. The rule row is
+// locked so concurrent tickets do not land on the same person. It returns
+// the chosen assignee.
+func (r *TicketRepository) AssignByRule(ruleID, ticketID uint) (string, error) {
+	var assigneeID string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var rule model.TicketAssignmentRule
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&rule, ruleID).Error
+		if err != nil {
+			return err
+		}
+
+		var staff []string
+		err = tx.Table("ticket_assignment_rule_staff").
+			Where("ticket_assignment_rule_id = ?", ruleID).
+			Order(`user_id COLLATE "C" ASC`).Pluck("user_id", &staff).Error
+		if err != nil {
+			return err
+		}
+		if len(staff) == 0 {
+			return fmt.Errorf("assignment rule %d has no staff", ruleID)
+		}
+
+		assigneeID = staff[0]
+		if rule.LastAssigneeID != nil {
+			for i, id := range staff {
+				if id > *rule.LastAssigneeID {
+					assigneeID = staff[i]
+					break
+				}
+			}
+		}
+
+		err = tx.Model(&rule).Update("last_assignee_id", assigneeID).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&model.Ticket{}).Where("id = ?", ticketID).Updates(map[string]interface{}{
+			"assignee_id":    assigneeID,
+			"assigned_by_id": nil,
+			"assigned_at":    time.Now(),
+		}).Error
+	})
+	return assigneeID, err
+}
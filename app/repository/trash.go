@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Shared helpers for soft-deleted rows. Every model that embeds
+// gorm.DeletedAt is hidden from normal queries, so these go through
+// Unscoped and filter on deleted_at explicitly.
+
+func getDeleted[T any](db *gorm.DB, limit, offset int) ([]T, int64, error) {
+	var items []T
+	var total int64
+
+	query := db.Unscoped().Model(new(T)).Where("deleted_at IS NOT NULL")
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Order("deleted_at DESC").
+		Limit(limit).Offset(offset).Find(&items).Error
+
+	return items, total, err
+}
+
+func restore[T any](db *gorm.DB, id interface{}) error {
+	result := db.Unscoped().Model(new(T)).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// purgeDeleted removes rows trashed before the cutoff one at a time, so a
+// row that live records still reference only keeps itself in the trash.
+// Those rows are skipped and retried on the next run.
+func purgeDeleted[T any](db *gorm.DB, before time.Time) (int64, error) {
+	var items []T
+	err := db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Find(&items).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	for i := range items {
+		result := db.Unscoped().Delete(&items[i])
+		if isForeignKeyViolation(result.Error) {
+			continue
+		}
+		if result.Error != nil {
+			return purged, result.Error
+		}
+		purged += result.RowsAffected
+	}
+	return purged, nil
+}
+
+// foreignKeyViolation is the Postgres SQLSTATE for a delete or update
+// that would leave a reference dangling.
+const foreignKeyViolation = "23503"
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/database"
+	"fmt"
+	"time"
 	"gorm.io/gorm"
 )
 
@@ -16,21 +18,57 @@ func NewUserRepository() *UserRepository {
 	}
 }
 
-func (r *UserRepository) GetAll(limit, offset int) ([]model.User, int64, error) {
+func (r *UserRepository) GetAll(limit, offset int, filter *model.UserFilter) ([]model.User, int64, error) {
 	var users []model.User
 	var total int64
 
-	err := r.db.Model(&model.User{}).Count(&total).Error
+	query := r.applyFilter(r.db.Model(&model.User{}), filter)
+
+	err := query.Count(&total).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
-	err = r.db.Preload("Role").Preload("Village").
+	err = query.Preload("Role").Preload("Village").
 		Limit(limit).Offset(offset).Find(&users).Error
 
 	return users, total, err
 }
 
+func (r *UserRepository) applyFilter(query *gorm.DB, filter *model.UserFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+
+	if filter.VillageID != nil {
+		query = query.Where("village_id = ?", *filter.VillageID)
+	}
+	if !filter.RegionFilter.IsEmpty() {
+		query = query.Where("village_id IN (?)", regionVillageIDs(r.db, &filter.RegionFilter))
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+
+	columns := map[string]string{
+		"card_status":    filter.CardStatus,
+		"gender":         filter.Gender,
+		"occupation":     filter.Occupation,
+		"education":      filter.Education,
+		"marital_status": filter.MaritalStatus,
+		"ranting":        filter.Ranting,
+		"cabang":         filter.Cabang,
+		"daerah":         filter.Daerah,
+	}
+	for column, value := range columns {
+		if value != "" {
+			query = query.Where(column+" = ?", value)
+		}
+	}
+
+	return query
+}
+
 func (r *UserRepository) GetByID(id string) (*model.User, error) {
 	var user model.User
 	err := r.db.Preload("Role").Preload("Village").
@@ -38,14 +76,85 @@ func (r *UserRepository) GetByID(id string) (*model.User, error) {
 	return &user, err
 }
 
+// GetAccountStatus loads only the columns needed to decide whether an
+// account may sign in, for the per-request check in Authorization.
+func (r *UserRepository) GetAccountStatus(id string) (*model.User, error) {
+	var user model.User
+	err := r.db.Select("id", "is_active", "suspended_until").
+		First(&user, "id = ?", id).Error
+	return &user, err
+}
+
+func (r *UserRepository) GetByReferralCode(code string) (*model.User, error) {
+	var user model.User
+	err := r.db.First(&user, "referral_code = ?", code).Error
+	return &user, err
+}
+
+func (r *UserRepository) ReferralCodeExists(code string) bool {
+	var count int64
+	r.db.Unscoped().Model(&model.User{}).Where("referral_code = ?", code).Count(&count)
+	return count > 0
+}
+
 func (r *UserRepository) Create(user *model.User) error {
 	return r.db.Create(user).Error
 }
 
+// CreateWithMemberNumber takes the next sequence value for the member's
+// village and year, formats it into user.ID and creates the member in one
+// transaction. The upsert keeps the sequence row locked until commit, so
+// concurrent sign-ups in a village queue up, and a failed insert rolls the
+// counter back instead of leaving a gap. Numbers already taken by legacy
+// IDs are skipped.
+func (r *UserRepository) CreateWithMemberNumber(user *model.User, year int, format func(villageCode string, seq int) string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var villageID uint
+		var villageCode string
+		if user.VillageID != nil {
+			var village model.Village
+			if err := tx.Select("id", "code").First(&village, *user.VillageID).Error; err != nil {
+				return fmt.Errorf("village %d not found", *user.VillageID)
+			}
+			villageID, villageCode = village.ID, village.Code
+		}
+
+		for {
+			var seq int
+			err := tx.Raw(`INSERT INTO member_sequences (village_id, year, last_value, updated_at)
+				VALUES (?, ?, 1, NOW())
+				ON CONFLICT (village_id, year)
+				DO UPDATE SET last_value = member_sequences.last_value + 1, updated_at = NOW()
+				RETURNING last_value`, villageID, year).Scan(&seq).Error
+			if err != nil {
+				return err
+			}
+
+			user.ID = format(villageCode, seq)
+
+			var count int64
+			if err := tx.Unscoped().Model(&model.User{}).Where("id = ?", user.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				break
+			}
+		}
+
+		return tx.Create(user).Error
+	})
+}
+
 func (r *UserRepository) Update(id string, user *model.User) error {
 	return r.db.Where("id = ?", id).Updates(user).Error
 }
 
+// UpdateColumns sets the given columns, including ones being cleared to
+// NULL, which Update skips.
+func (r *UserRepository) UpdateColumns(id string, columns map[string]interface{}) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Updates(columns).Error
+}
+
 func (r *UserRepository) Delete(id string) error {
 	return r.db.Delete(&model.User{}, "id = ?", id).Error
 }
@@ -82,18 +191,22 @@ func (r *UserRepository) GetWithStats(limit, offset int) ([]model.UserWithStats,
 		LEFT JOIN (
 			SELECT user_id, COUNT(*) as article_count 
 			FROM articles 
+			WHERE deleted_at IS NULL
 			GROUP BY user_id
 		) a ON u.id = a.user_id
 		LEFT JOIN (
 			SELECT user_id, COUNT(*) as ticket_count 
 			FROM tickets 
+			WHERE deleted_at IS NULL
 			GROUP BY user_id
 		) t ON u.id = t.user_id
 		LEFT JOIN (
 			SELECT user_id, COUNT(*) as document_count 
 			FROM documents 
+			WHERE deleted_at IS NULL
 			GROUP BY user_id
 		) d ON u.id = d.user_id
+		WHERE u.deleted_at IS NULL
 		LIMIT ? OFFSET ?
 	`
 
@@ -116,13 +229,18 @@ func (r *UserRepository) GetByCardStatus(status string, limit, offset int) ([]mo
 	return users, total, err
 }
 
-func (r *UserRepository) GetCardStatusStats() (map[string]int64, error) {
+func (r *UserRepository) GetCardStatusStats(region *model.RegionFilter) (map[string]int64, error) {
 	var results []struct {
 		CardStatus string
 		Count      int64
 	}
 
-	err := r.db.Model(&model.User{}).
+	query := r.db.Model(&model.User{})
+	if !region.IsEmpty() {
+		query = query.Where("village_id IN (?)", regionVillageIDs(r.db, region))
+	}
+
+	err := query.
 		Select("card_status, count(*) as count").
 		Group("card_status").
 		Scan(&results).Error
@@ -137,4 +255,148 @@ func (r *UserRepository) GetCardStatusStats() (map[string]int64, error) {
 	}
 
 	return stats, nil
-}
\ No newline at end of file
+}
+
+// MaxBulkUsers caps how many members one bulk operation may touch.
+const MaxBulkUsers = 5000
+
+func (r *UserRepository) GetForBulk(ids []string, filter *model.UserFilter) ([]model.User, error) {
+	var users []model.User
+	query := r.db.Model(&model.User{})
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	} else {
+		query = r.applyFilter(query, filter)
+	}
+
+	err := query.Order("id ASC").Limit(MaxBulkUsers + 1).Find(&users).Error
+	return users, err
+}
+
+// UpdateCardStatuses applies every change and its history row in one
+// transaction. A member whose status moved since it was read aborts the
+// whole batch. Extra columns, if any, are set on every changed member.
+func (r *UserRepository) UpdateCardStatuses(changes []model.CardStatusHistory, extra map[string]interface{}) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range changes {
+			change := &changes[i]
+			columns := map[string]interface{}{"card_status": change.ToStatus}
+			for column, value := range extra {
+				columns[column] = value
+			}
+
+			result := tx.Model(&model.User{}).
+				Where("id = ? AND card_status = ?", change.UserID, change.FromStatus).
+				Updates(columns)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("card status of %s changed during the update", change.UserID)
+			}
+
+			if err := tx.Create(change).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) GetCardStatusHistory(userID string) ([]model.CardStatusHistory, error) {
+	var history []model.CardStatusHistory
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").Find(&history).Error
+	return history, err
+}
+
+// GetExpiredCards returns issued cards whose expiry date has passed.
+func (r *UserRepository) GetExpiredCards(now time.Time) ([]model.User, error) {
+	var users []model.User
+	err := r.db.Where("card_expires_at < ? AND card_status IN ?", now,
+		[]string{model.CardStatusPrinted, model.CardStatusDelivered}).
+		Find(&users).Error
+	return users, err
+}
+
+// GetCardsDueForReminder returns issued cards expiring before the given
+// time that have not been reminded yet.
+func (r *UserRepository) GetCardsDueForReminder(now, before time.Time) ([]model.User, error) {
+	var users []model.User
+	err := r.db.Where("card_expires_at >= ? AND card_expires_at < ? AND card_reminder_sent_at IS NULL AND card_status IN ?",
+		now, before, []string{model.CardStatusPrinted, model.CardStatusDelivered}).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepository) MarkCardReminderSent(id string, sentAt time.Time) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).
+		Update("card_reminder_sent_at", sentAt).Error
+}
+
+func (r *UserRepository) GetReferrals(referrerID string, limit, offset int) ([]model.User, int64, error) {
+	var users []model.User
+	var total int64
+
+	err := r.db.Model(&model.User{}).Where("referred_by = ?", referrerID).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = r.db.Preload("Village").Where("referred_by = ?", referrerID).
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).Find(&users).Error
+	return users, total, err
+}
+
+// ReferralLeaderboard ranks recruiters by how many of the members they
+// brought in during [from, to) have been approved.
+func (r *UserRepository) ReferralLeaderboard(from, to time.Time, villageID *uint, limit int) ([]model.ReferralLeaderboardEntry, error) {
+	var entries []model.ReferralLeaderboardEntry
+
+	query := r.db.Table("users AS m").
+		Select(`m.referred_by AS user_id, ref.name, ref.village_id, COALESCE(v.name, '') AS village_name,
+			COUNT(*) AS total_referred,
+			COUNT(*) FILTER (WHERE m.card_status IN ?) AS total_approved`, model.ApprovedCardStatuses).
+		Joins("JOIN users ref ON ref.id = m.referred_by").
+		Joins("LEFT JOIN villages v ON v.id = ref.village_id").
+		Where("m.deleted_at IS NULL AND m.created_at >= ? AND m.created_at < ?", from, to)
+	if villageID != nil {
+		query = query.Where("ref.village_id = ?", *villageID)
+	}
+
+	err := query.Group("m.referred_by, ref.name, ref.village_id, v.name").
+		Order("total_approved DESC, total_referred DESC").
+		Limit(limit).Scan(&entries).Error
+	return entries, err
+}
+
+// VillageReferralLeaderboard groups referred members by the recruiter's
+// village.
+func (r *UserRepository) VillageReferralLeaderboard(from, to time.Time) ([]model.VillageReferralEntry, error) {
+	var entries []model.VillageReferralEntry
+
+	err := r.db.Table("users AS m").
+		Select(`ref.village_id, COALESCE(v.name, '') AS village_name,
+			COUNT(*) AS total_referred,
+			COUNT(*) FILTER (WHERE m.card_status IN ?) AS total_approved`, model.ApprovedCardStatuses).
+		Joins("JOIN users ref ON ref.id = m.referred_by").
+		Joins("LEFT JOIN villages v ON v.id = ref.village_id").
+		Where("m.deleted_at IS NULL AND m.created_at >= ? AND m.created_at < ?", from, to).
+		Group("ref.village_id, v.name").
+		Order("total_approved DESC, total_referred DESC").
+		Scan(&entries).Error
+	return entries, err
+}
+
+func (r *UserRepository) GetDeleted(limit, offset int) ([]model.User, int64, error) {
+	return getDeleted[model.User](r.db, limit, offset)
+}
+
+func (r *UserRepository) Restore(id string) error {
+	return restore[model.User](r.db, id)
+}
+
+func (r *UserRepository) PurgeDeleted(before time.Time) (int64, error) {
+	return purgeDeleted[model.User](r.db, before)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/database"
+	"time"
 	"gorm.io/gorm"
 )
 
@@ -16,7 +17,7 @@ func NewVillageRepository() *VillageRepository {
 	}
 }
 
-func (r *VillageRepository) GetAll(limit, offset int, activeOnly bool) ([]model.Village, int64, error) {
+func (r *VillageRepository) GetAll(limit, offset int, activeOnly bool, region *model.RegionFilter) ([]model.Village, int64, error) {
 	var villages []model.Village
 	var total int64
 
@@ -24,6 +25,9 @@ func (r *VillageRepository) GetAll(limit, offset int, activeOnly bool) ([]model.
 	if activeOnly {
 		query = query.Where("is_active = ?", true)
 	}
+	if !region.IsEmpty() {
+		query = query.Where("id IN (?)", regionVillageIDs(r.db, region))
+	}
 
 	err := query.Count(&total).Error
 	if err != nil {
@@ -42,6 +46,65 @@ func (r *VillageRepository) GetByID(id uint) (*model.Village, error) {
 	return &village, err
 }
 
+func (r *VillageRepository) GetByCode(code string) (*model.Village, error) {
+	var village model.Village
+	err := r.db.Where("code = ?", code).First(&village).Error
+	return &village, err
+}
+
+// GetByCodes also returns soft-deleted villages, whose codes are still
+// taken by the unique index.
+func (r *VillageRepository) GetByCodes(codes []string) ([]model.Village, error) {
+	var villages []model.Village
+	err := r.db.Unscoped().Where("code IN ?", codes).Find(&villages).Error
+	return villages, err
+}
+
+// Import creates and updates villages in one transaction, so a failing row
+// leaves every village as it was.
+func (r *VillageRepository) Import(creates []model.Village, updates map[uint]map[string]interface{}) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(creates) > 0 {
+			if err := tx.Create(&creates).Error; err != nil {
+				return err
+			}
+			// Create skips a false IsActive in favour of the column default
+			for _, village := range creates {
+				if village.IsActive {
+					continue
+				}
+				if err := tx.Model(&model.Village{}).Where("id = ?", village.ID).Update("is_active", false).Error; err != nil {
+					return err
+				}
+			}
+		}
+		for id, columns := range updates {
+			if err := tx.Model(&model.Village{}).Where("id = ?", id).Updates(columns).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SetActive activates or deactivates every village matching one of the IDs
+// or codes and returns how many were changed.
+func (r *VillageRepository) SetActive(ids []uint, codes []string, active bool) (int64, error) {
+	result := r.db.Model(&model.Village{}).
+		Where("id IN ? OR code IN ?", ids, codes).
+		Update("is_active", active)
+	return result.RowsAffected, result.Error
+}
+
+func (r *VillageRepository) SetBoundary(id uint, boundary string, lat, lng float64) error {
+	return r.db.Model(&model.Village{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"boundary":     boundary,
+			"centroid_lat": lat,
+			"centroid_lng": lng,
+		}).Error
+}
+
 func (r *VillageRepository) Create(village *model.Village) error {
 	return r.db.Create(village).Error
 }
@@ -54,22 +117,297 @@ func (r *VillageRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Village{}, id).Error
 }
 
+// CountReferences includes members in the trash, since restoring them
+// would bring the reference back.
+func (r *VillageRepository) CountReferences(id uint) (model.VillageReferences, error) {
+	var refs model.VillageReferences
+
+	counts := []struct {
+		query *gorm.DB
+		total *int64
+	}{
+		{r.db.Unscoped().Model(&model.User{}).Where("village_id = ?", id), &refs.Members},
+		{r.db.Model(&model.Household{}).Where("village_id = ?", id), &refs.Households},
+		{r.db.Model(&model.DuesSchedule{}).Where("village_id = ? AND is_active = ?", id, true), &refs.DuesSchedules},
+		{r.db.Model(&model.ProfileChangeRequest{}).
+			Where("village_id = ? AND status = ?", id, model.ChangeRequestPending), &refs.PendingChanges},
+		{r.db.Model(&model.TicketAssignmentRule{}).Where("village_id = ?", id), &refs.AssignmentRules},
+	}
+	for _, count := range counts {
+		if err := count.query.Count(count.total).Error; err != nil {
+			return refs, err
+		}
+	}
+	return refs, nil
+}
+
+// ArchiveInto moves members, households and pending village changes to the
+// target and archives the village, all in one transaction. Moving the
+// members also moves their tickets. The village's own dues schedules are
+// deactivated rather than moved so the target keeps its rates. Its
+// coordinators' assignment rule moves over unless the target has its own,
+// in which case it is deactivated, and the target inherits the village's
+// branch if it has none.
+func (r *VillageRepository) ArchiveInto(id, targetID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var village, target model.Village
+		if err := tx.First(&village, id).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&target, targetID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Unscoped().Model(&model.User{}).Where("village_id = ?", id).
+			Update("village_id", targetID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.Household{}).Where("village_id = ?", id).
+			Update("village_id", targetID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.ProfileChangeRequest{}).
+			Where("village_id = ? AND status = ?", id, model.ChangeRequestPending).
+			Update("village_id", targetID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.DuesSchedule{}).Where("village_id = ?", id).
+			Update("is_active", false).Error
+		if err != nil {
+			return err
+		}
+
+		var targetRules int64
+		err = tx.Model(&model.TicketAssignmentRule{}).Where("village_id = ?", targetID).
+			Count(&targetRules).Error
+		if err != nil {
+			return err
+		}
+		rules := tx.Model(&model.TicketAssignmentRule{}).Where("village_id = ?", id)
+		if targetRules > 0 {
+			err = rules.Update("is_active", false).Error
+		} else {
+			err = rules.Update("village_id", targetID).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		if target.BranchID == nil && village.BranchID != nil {
+			err = tx.Model(&model.Village{}).Where("id = ?", targetID).
+				Update("branch_id", *village.BranchID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&model.Village{}).Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"is_active":      false,
+				"archived_at":    time.Now(),
+				"merged_into_id": targetID,
+				"branch_id":      nil,
+			}).Error
+	})
+}
+
 func (r *VillageRepository) GetWithUserCount() ([]model.VillageWithUserCount, error) {
 	var villages []model.VillageWithUserCount
 
 	query := `
-		SELECT v.*, COALESCE(user_count, 0) as total_users
+		SELECT v.*, COALESCE(user_count, 0) as total_users,
+			   COALESCE(household_count, 0) as total_households
 		FROM villages v
 		LEFT JOIN (
 			SELECT village_id, COUNT(*) as user_count 
 			FROM users 
-			WHERE village_id IS NOT NULL
+			WHERE village_id IS NOT NULL AND deleted_at IS NULL
 			GROUP BY village_id
 		) u ON v.id = u.village_id
-		WHERE v.is_active = true
+		LEFT JOIN (
+			SELECT village_id, COUNT(*) as household_count
+			FROM households
+			WHERE village_id IS NOT NULL
+			GROUP BY village_id
+		) h ON v.id = h.village_id
+		WHERE v.is_active = true AND v.deleted_at IS NULL
 		ORDER BY v.name ASC
 	`
 
 	err := r.db.Raw(query).Scan(&villages).Error
 	return villages, err
-}
\ No newline at end of file
+}
+
+// GetCardStatusCounts returns, per village, how many members are in each
+// card status.
+func (r *VillageRepository) GetCardStatusCounts() (map[uint]map[string]int64, error) {
+	var results []struct {
+		VillageID  uint
+		CardStatus string
+		Count      int64
+	}
+
+	err := r.db.Model(&model.User{}).
+		Select("village_id, card_status, count(*) as count").
+		Where("village_id IS NOT NULL").
+		Group("village_id, card_status").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]map[string]int64)
+	for _, result := range results {
+		if counts[result.VillageID] == nil {
+			counts[result.VillageID] = make(map[string]int64)
+		}
+		counts[result.VillageID][result.CardStatus] = result.Count
+	}
+	return counts, nil
+}
+
+func (r *VillageRepository) GetDeleted(limit, offset int) ([]model.Village, int64, error) {
+	return getDeleted[model.Village](r.db, limit, offset)
+}
+
+func (r *VillageRepository) Restore(id uint) error {
+	return restore[model.Village](r.db, id)
+}
+
+func (r *VillageRepository) PurgeDeleted(before time.Time) (int64, error) {
+	return purgeDeleted[model.Village](r.db, before)
+}
+
+// ageBracketExpr buckets members by age at the time of the query.
+const ageBracketExpr = `CASE
+	WHEN u.birth_date IS NULL THEN 'unknown'
+	WHEN date_part('year', age(u.birth_date)) < 17 THEN 'under_17'
+	WHEN date_part('year', age(u.birth_date)) <= 25 THEN '17_25'
+	WHEN date_part('year', age(u.birth_date)) <= 35 THEN '26_35'
+	WHEN date_part('year', age(u.birth_date)) <= 45 THEN '36_45'
+	WHEN date_part('year', age(u.birth_date)) <= 55 THEN '46_55'
+	WHEN date_part('year', age(u.birth_date)) <= 65 THEN '56_65'
+	ELSE 'over_65'
+END`
+
+type villageBucket struct {
+	VillageID uint
+	Bucket    string
+	Count     int64
+}
+
+// GetStats builds VillageStats for one village, or for every active
+// village when villageID is nil. Registration months start at since.
+func (r *VillageRepository) GetStats(villageID *uint, since time.Time) ([]model.VillageStats, error) {
+	var villages []model.Village
+	query := r.db.Model(&model.Village{})
+	if villageID != nil {
+		query = query.Where("id = ?", *villageID)
+	} else {
+		query = query.Where("is_active = ?", true)
+	}
+	if err := query.Order("name ASC").Find(&villages).Error; err != nil {
+		return nil, err
+	}
+
+	stats := make([]model.VillageStats, len(villages))
+	byID := make(map[uint]*model.VillageStats, len(villages))
+	for i, village := range villages {
+		stats[i] = model.VillageStats{
+			VillageID:           village.ID,
+			VillageName:         village.Name,
+			VillageCode:         village.Code,
+			ByCardStatus:        map[string]int64{},
+			ByGender:            map[string]int64{},
+			ByAgeBracket:        map[string]int64{},
+			ByRole:              map[string]int64{},
+			ByRegistrationMonth: map[string]int64{},
+		}
+		byID[village.ID] = &stats[i]
+	}
+
+	members := func() *gorm.DB {
+		q := r.db.Table("users AS u").Where("u.deleted_at IS NULL AND u.village_id IS NOT NULL")
+		if villageID != nil {
+			q = q.Where("u.village_id = ?", *villageID)
+		}
+		return q
+	}
+
+	breakdowns := []struct {
+		query  *gorm.DB
+		target func(*model.VillageStats) map[string]int64
+	}{
+		{members().Select("u.village_id, u.card_status AS bucket, COUNT(*) AS count"),
+			func(s *model.VillageStats) map[string]int64 { return s.ByCardStatus }},
+		{members().Select("u.village_id, COALESCE(u.gender, 'unknown') AS bucket, COUNT(*) AS count"),
+			func(s *model.VillageStats) map[string]int64 { return s.ByGender }},
+		{members().Select("u.village_id, " + ageBracketExpr + " AS bucket, COUNT(*) AS count"),
+			func(s *model.VillageStats) map[string]int64 { return s.ByAgeBracket }},
+		{members().Joins("LEFT JOIN roles ro ON ro.id = u.role_id").
+			Select("u.village_id, COALESCE(ro.name, 'unknown') AS bucket, COUNT(*) AS count"),
+			func(s *model.VillageStats) map[string]int64 { return s.ByRole }},
+		{members().Where("u.created_at >= ?", since).
+			Select("u.village_id, to_char(u.created_at, 'YYYY-MM') AS bucket, COUNT(*) AS count"),
+			func(s *model.VillageStats) map[string]int64 { return s.ByRegistrationMonth }},
+	}
+
+	for _, breakdown := range breakdowns {
+		var buckets []villageBucket
+		if err := breakdown.query.Group("u.village_id, bucket").Scan(&buckets).Error; err != nil {
+			return nil, err
+		}
+		for _, bucket := range buckets {
+			if s, ok := byID[bucket.VillageID]; ok {
+				breakdown.target(s)[bucket.Bucket] = bucket.Count
+			}
+		}
+	}
+
+	var totals []struct {
+		VillageID     uint
+		Total         int64
+		WithDocuments int64
+		WithNIK       int64
+		WithPhoto     int64
+		OpenTickets   int64
+	}
+	err := members().
+		Select(`u.village_id, COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM documents d WHERE d.user_id = u.id AND d.deleted_at IS NULL)) AS with_documents,
+			COUNT(u.nik) AS with_nik,
+			COUNT(u.photo_url) AS with_photo,
+			COALESCE(SUM((SELECT COUNT(*) FROM tickets t
+				WHERE t.user_id = u.id AND t.deleted_at IS NULL AND t.status NOT IN ?)), 0) AS open_tickets`,
+			[]model.TicketStatus{model.TicketStatusResolved, model.TicketStatusClosed}).
+		Group("u.village_id").Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, total := range totals {
+		s, ok := byID[total.VillageID]
+		if !ok {
+			continue
+		}
+		s.TotalMembers = total.Total
+		s.OpenTickets = total.OpenTickets
+		s.Documents = model.DocumentCompleteness{
+			MembersWithDocuments: total.WithDocuments,
+			MembersWithNIK:       total.WithNIK,
+			MembersWithPhoto:     total.WithPhoto,
+		}
+		if total.Total > 0 {
+			s.Documents.Percentage = float64(total.WithDocuments) * 100 / float64(total.Total)
+		}
+	}
+
+	return stats, nil
+}
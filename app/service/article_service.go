@@ -41,8 +41,7 @@ func (s *ArticleService) CreateArticle(userID string, req *model.CreateArticleRe
 	slug := helper.GenerateSlug(req.Title)
 	
 	// Check if slug already exists
-	_, err := s.articleRepo.GetBySlug(slug)
-	if err == nil {
+	if s.articleRepo.SlugExists(slug) {
 		slug = helper.GenerateUniqueSlug(req.Title)
 	}
 
@@ -56,7 +55,7 @@ func (s *ArticleService) CreateArticle(userID string, req *model.CreateArticleRe
 		IsPublished:   helper.GetBoolValue(req.IsPublished, false),
 	}
 
-	err = s.articleRepo.Create(article)
+	err := s.articleRepo.Create(article)
 	if err != nil {
 		return nil, err
 	}
@@ -74,8 +73,7 @@ func (s *ArticleService) UpdateArticle(id uint, req *model.CreateArticleRequest)
 	if req.Title != existing.Title {
 		slug = helper.GenerateSlug(req.Title)
 		// Check if new slug already exists
-		existingBySlug, _ := s.articleRepo.GetBySlug(slug)
-		if existingBySlug != nil && existingBySlug.ID != id {
+		if slug != existing.Slug && s.articleRepo.SlugExists(slug) {
 			slug = helper.GenerateUniqueSlug(req.Title)
 		}
 	} else {
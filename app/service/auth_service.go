@@ -3,7 +3,9 @@ package service
 import (
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/helper"
 	"arek-muhammadiyah-be/helper/utils"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -36,13 +38,26 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	if !utils.CheckPasswordHash(req.Password, user.Password) {
+	// Invited accounts have no password yet. They get the same answer as
+	// unknown IDs so the response does not reveal who has been invited.
+	if user.Password == "" || !utils.CheckPasswordHash(req.Password, user.Password) {
     return c.Status(fiber.StatusUnauthorized).JSON(model.Response{
         Success: false,
         Message: "Invalid credentials",
     })
 }
 
+	if user.IsSuspended(time.Now()) {
+		message := "Account is suspended"
+		if user.SuspendedReason != nil {
+			message += ": " + *user.SuspendedReason
+		}
+		return c.Status(fiber.StatusForbidden).JSON(model.Response{
+			Success: false,
+			Message: message,
+		})
+	}
+
 	token, err := utils.GenerateToken(user.ID, user.RoleID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
@@ -80,8 +95,8 @@ func (s *AuthService) Register(c *fiber.Ctx) error {
 		})
 	}
 
+	// Self-registered members always get a generated member number
 	user := &model.User{
-		ID:        req.ID,
 		Name:      req.Name,
 		Password:  hashedPassword,
 		RoleID:    req.RoleID,
@@ -90,7 +105,24 @@ func (s *AuthService) Register(c *fiber.Ctx) error {
 		Address:   req.Address,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	if err := helper.ApplyUserProfile(user, &req.UserProfileRequest); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	referrer, err := resolveReferrer(s.userRepo, &req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	user.ReferredBy = referrer
+	user.ReferralCode = newReferralCode(s.userRepo)
+
+	if err := createMember(s.userRepo, user); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
 			Success: false,
 			Message: err.Error(),
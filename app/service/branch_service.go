@@ -0,0 +1,463 @@
+package service
+
+import (
+	"errors"
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/helper"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BranchService struct {
+	branchRepo *repository.BranchRepository
+	userRepo   *repository.UserRepository
+}
+
+func NewBranchService() *BranchService {
+	return &BranchService{
+		branchRepo: repository.NewBranchRepository(),
+		userRepo:   repository.NewUserRepository(),
+	}
+}
+
+func (s *BranchService) GetAll(c *fiber.Ctx) error {
+	var parentID *uint
+	if parentIDStr := c.Query("parent_id"); parentIDStr != "" {
+		id, _ := strconv.ParseUint(parentIDStr, 10, 32)
+		p := uint(id)
+		parentID = &p
+	}
+
+	branches, err := s.branchRepo.GetAll(strings.ToLower(c.Query("level")), parentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Branches retrieved successfully",
+		Data:    branches,
+	})
+}
+
+func (s *BranchService) GetByID(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	branch, err := s.branchRepo.GetByID(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Branch not found",
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Branch retrieved successfully",
+		Data:    branch,
+	})
+}
+
+func (s *BranchService) Create(c *fiber.Ctx) error {
+	return s.save(c, &model.Branch{}, fiber.StatusCreated, "Branch created successfully")
+}
+
+func (s *BranchService) Update(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	branch, err := s.branchRepo.GetByID(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Branch not found",
+		})
+	}
+	return s.save(c, branch, fiber.StatusOK, "Branch updated successfully")
+}
+
+func (s *BranchService) save(c *fiber.Ctx, branch *model.Branch, status int, message string) error {
+	var req model.CreateBranchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if err := s.applyBranch(branch, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	if err := s.branchRepo.Save(branch, req.VillageIDs); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	saved, _ := s.branchRepo.GetByID(branch.ID)
+	return c.Status(status).JSON(model.Response{
+		Success: true,
+		Message: message,
+		Data:    saved,
+	})
+}
+
+// applyBranch copies the request onto branch and checks the parent sits
+// one level up: PRM under PCM, PCM under PDM, PDM at the top.
+func (s *BranchService) applyBranch(branch *model.Branch, req *model.CreateBranchRequest) error {
+	if req.Name != "" {
+		branch.Name = req.Name
+	}
+	if req.Level != "" {
+		branch.Level = strings.ToLower(req.Level)
+	}
+	branch.ParentID = helper.GetUintPointer(req.ParentID, branch.ParentID)
+	branch.Address = helper.GetStringPointer(req.Address, branch.Address)
+
+	if branch.Name == "" {
+		return errors.New("name is required")
+	}
+	parentLevel, ok := model.BranchParentLevel[branch.Level]
+	if !ok {
+		return errors.New("level must be prm, pcm or pdm")
+	}
+
+	if parentLevel == "" {
+		branch.ParentID = nil
+		return nil
+	}
+	if branch.ParentID == nil {
+		return errors.New("a " + branch.Level + " needs a parent " + parentLevel)
+	}
+	parent, err := s.branchRepo.GetByID(*branch.ParentID)
+	if err != nil {
+		return errors.New("parent branch not found")
+	}
+	if parent.Level != parentLevel {
+		return errors.New("the parent of a " + branch.Level + " must be a " + parentLevel)
+	}
+	return nil
+}
+
+func (s *BranchService) Delete(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	if _, err := s.branchRepo.GetByID(uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Branch not found",
+		})
+	}
+
+	dependents, err := s.branchRepo.CountDependents(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if dependents > 0 {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: "Branch still has sub-branches or officer history",
+		})
+	}
+
+	if err := s.branchRepo.Delete(uint(id)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Branch deleted successfully",
+	})
+}
+
+// GetOfficers splits the branch's officer terms into current and past as
+// of today. Terms that have not started yet are listed as upcoming.
+func (s *BranchService) GetOfficers(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	if _, err := s.branchRepo.GetByID(uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Branch not found",
+		})
+	}
+
+	terms, err := s.branchRepo.GetTerms(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	now := time.Now()
+	current := []model.OfficerTerm{}
+	past := []model.OfficerTerm{}
+	upcoming := []model.OfficerTerm{}
+	for _, term := range terms {
+		switch {
+		case term.IsCurrent(now):
+			current = append(current, term)
+		case term.StartDate.After(now):
+			upcoming = append(upcoming, term)
+		default:
+			past = append(past, term)
+		}
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Branch officers retrieved successfully",
+		Data: helper.MaskSensitive(c, fiber.Map{
+			"current":  current,
+			"past":     past,
+			"upcoming": upcoming,
+		}),
+	})
+}
+
+func (s *BranchService) AssignOfficer(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	branch, err := s.branchRepo.GetByID(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Branch not found",
+		})
+	}
+
+	createdBy := c.Locals("user_id").(string)
+	term := &model.OfficerTerm{BranchID: branch.ID, CreatedBy: &createdBy}
+	return s.saveTerm(c, term, fiber.StatusCreated, "Officer assigned successfully")
+}
+
+func (s *BranchService) UpdateOfficer(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("termId"), 10, 32)
+	term, err := s.branchRepo.GetTermByID(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Officer term not found",
+		})
+	}
+	return s.saveTerm(c, term, fiber.StatusOK, "Officer term updated successfully")
+}
+
+func (s *BranchService) saveTerm(c *fiber.Ctx, term *model.OfficerTerm, status int, message string) error {
+	var req model.OfficerTermRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if err := s.applyTerm(term, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	if err := s.branchRepo.SaveTerm(term); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	saved, _ := s.branchRepo.GetTermByID(term.ID)
+	return c.Status(status).JSON(model.Response{
+		Success: true,
+		Message: message,
+		Data:    helper.MaskSensitive(c, saved),
+	})
+}
+
+// applyTerm copies the request onto term and rejects a term that overlaps
+// another holder of a single-holder position in the same branch.
+func (s *BranchService) applyTerm(term *model.OfficerTerm, req *model.OfficerTermRequest) error {
+	if req.PositionID != 0 {
+		term.PositionID = req.PositionID
+	}
+	if req.UserID != "" {
+		term.UserID = req.UserID
+	}
+	if req.StartDate != "" {
+		startDate, err := helper.ParseDate(req.StartDate)
+		if err != nil {
+			return err
+		}
+		term.StartDate = *startDate
+	}
+	if req.EndDate != nil {
+		term.EndDate = nil
+		if *req.EndDate != "" {
+			endDate, err := helper.ParseDate(*req.EndDate)
+			if err != nil {
+				return err
+			}
+			term.EndDate = endDate
+		}
+	}
+	term.Note = helper.GetStringPointer(req.Note, term.Note)
+
+	if term.PositionID == 0 || term.UserID == "" || term.StartDate.IsZero() {
+		return errors.New("position_id, user_id and start_date are required")
+	}
+	if term.EndDate != nil && term.EndDate.Before(term.StartDate) {
+		return errors.New("end_date must not be before start_date")
+	}
+
+	position, err := s.branchRepo.GetPositionByID(term.PositionID)
+	if err != nil {
+		return errors.New("position not found")
+	}
+	if _, err := s.userRepo.GetByID(term.UserID); err != nil {
+		return errors.New("user not found")
+	}
+
+	if !position.MultipleHolders {
+		overlap, err := s.branchRepo.HasOverlappingTerm(term.BranchID, term.PositionID, term.StartDate, term.EndDate, term.ID)
+		if err != nil {
+			return err
+		}
+		if overlap {
+			return errors.New(position.Name + " is already held by another officer in this period")
+		}
+	}
+	return nil
+}
+
+func (s *BranchService) DeleteOfficer(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("termId"), 10, 32)
+	if _, err := s.branchRepo.GetTermByID(uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Officer term not found",
+		})
+	}
+
+	if err := s.branchRepo.DeleteTerm(uint(id)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Officer term deleted successfully",
+	})
+}
+
+func (s *BranchService) GetPositions(c *fiber.Ctx) error {
+	positions, err := s.branchRepo.GetPositions()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Positions retrieved successfully",
+		Data:    positions,
+	})
+}
+
+func (s *BranchService) CreatePosition(c *fiber.Ctx) error {
+	return s.savePosition(c, &model.Position{}, fiber.StatusCreated, "Position created successfully")
+}
+
+func (s *BranchService) UpdatePosition(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	position, err := s.branchRepo.GetPositionByID(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Position not found",
+		})
+	}
+	return s.savePosition(c, position, fiber.StatusOK, "Position updated successfully")
+}
+
+func (s *BranchService) savePosition(c *fiber.Ctx, position *model.Position, status int, message string) error {
+	var req model.CreatePositionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.Name != "" {
+		position.Name = req.Name
+	}
+	if position.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "name is required",
+		})
+	}
+	if req.SortOrder != nil {
+		position.SortOrder = *req.SortOrder
+	}
+	position.MultipleHolders = helper.GetBoolValue(req.MultipleHolders, position.MultipleHolders)
+
+	if err := s.branchRepo.SavePosition(position); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(status).JSON(model.Response{
+		Success: true,
+		Message: message,
+		Data:    position,
+	})
+}
+
+func (s *BranchService) DeletePosition(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	if _, err := s.branchRepo.GetPositionByID(uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Position not found",
+		})
+	}
+
+	if s.branchRepo.PositionInUse(uint(id)) {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: "Position is used by officer terms",
+		})
+	}
+
+	if err := s.branchRepo.DeletePosition(uint(id)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Position deleted successfully",
+	})
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/config"
+	"arek-muhammadiyah-be/helper"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CardService struct {
+	userRepo        *repository.UserRepository
+	renewalRepo     *repository.CardRenewalRepository
+	notificationSvc *NotificationService
+}
+
+func NewCardService() *CardService {
+	return &CardService{
+		userRepo:        repository.NewUserRepository(),
+		renewalRepo:     repository.NewCardRenewalRepository(),
+		notificationSvc: NewNotificationService(),
+	}
+}
+
+// RequestRenewal lets a member ask for a new card once it has expired or
+// is inside the reminder window.
+func (s *CardService) RequestRenewal(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	var req model.CardRenewalRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "User not found",
+		})
+	}
+
+	reminderDays := helper.AtoiDefault(config.AppConfig.CardReminderDays, 30)
+	renewable := user.CardStatus == model.CardStatusExpired ||
+		(user.CardExpiresAt != nil && time.Until(*user.CardExpiresAt) <= time.Duration(reminderDays)*24*time.Hour)
+	if !renewable {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Card is not due for renewal yet",
+		})
+	}
+
+	if s.renewalRepo.HasPending(userID) {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: "A renewal request is already pending",
+		})
+	}
+
+	renewal := &model.CardRenewal{
+		UserID: userID,
+		Note:   req.Note,
+		Status: model.ChangeRequestPending,
+	}
+	if err := s.renewalRepo.Create(renewal); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.Response{
+		Success: true,
+		Message: "Renewal requested successfully",
+		Data:    helper.MaskSensitive(c, renewal),
+	})
+}
+
+func (s *CardService) GetMyRenewals(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	renewals, err := s.renewalRepo.GetByUserID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Renewal requests retrieved successfully",
+		Data:    helper.MaskSensitive(c, renewals),
+	})
+}
+
+func (s *CardService) GetRenewals(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	status := c.Query("status", model.ChangeRequestPending)
+	offset := (page - 1) * limit
+
+	renewals, total, err := s.renewalRepo.GetAll(limit, offset, status)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	pagination := helper.CreatePagination(int64(page), int64(limit), total)
+
+	return c.JSON(model.PaginatedResponse{
+		Success:    true,
+		Message:    "Renewal requests retrieved successfully",
+		Data:       helper.MaskSensitive(c, renewals),
+		Pagination: pagination,
+	})
+}
+
+func (s *CardService) ApproveRenewal(c *fiber.Ctx) error {
+	return s.reviewRenewal(c, true)
+}
+
+func (s *CardService) RejectRenewal(c *fiber.Ctx) error {
+	return s.reviewRenewal(c, false)
+}
+
+func (s *CardService) reviewRenewal(c *fiber.Ctx, approve bool) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	reviewerID := c.Locals("user_id").(string)
+
+	var req model.ReviewChangeRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	renewal, err := s.renewalRepo.GetByID(uint(id))
+	if err != nil || renewal.User == nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Renewal request not found",
+		})
+	}
+
+	if renewal.Status != model.ChangeRequestPending {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: "Renewal request has already been reviewed",
+		})
+	}
+
+	if approve && !model.CanTransitionCardStatus(renewal.User.CardStatus, model.CardStatusApproved) {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: "Cannot renew a card with status " + renewal.User.CardStatus,
+		})
+	}
+
+	if approve {
+		err = s.renewalRepo.Approve(renewal, renewal.User.CardStatus, reviewerID, req.Note)
+	} else {
+		err = s.renewalRepo.Reject(renewal.ID, reviewerID, req.Note)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	if approve {
+		s.notificationSvc.Notify(renewal.UserID, "card_renewal", "Card renewal approved",
+			"Your membership card renewal was approved and will be reprinted.")
+	} else {
+		s.notificationSvc.Notify(renewal.UserID, "card_renewal", "Card renewal rejected",
+			"Your membership card renewal was rejected. Please contact your branch.")
+	}
+
+	renewal, _ = s.renewalRepo.GetByID(uint(id))
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Renewal request reviewed successfully",
+		Data:    helper.MaskSensitive(c, renewal),
+	})
+}
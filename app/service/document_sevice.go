@@ -37,7 +37,7 @@ func (s *DocumentService) GetAll(c *fiber.Ctx) error {
 	return c.JSON(model.PaginatedResponse{
 		Success:    true,
 		Message:    "Documents retrieved successfully",
-		Data:       documents,
+		Data:       helper.MaskSensitive(c, documents),
 		Pagination: pagination,
 	})
 }
@@ -55,7 +55,7 @@ func (s *DocumentService) GetByID(c *fiber.Ctx) error {
 	return c.JSON(model.Response{
 		Success: true,
 		Message: "Document retrieved successfully",
-		Data:    document,
+		Data:    helper.MaskSensitive(c, document),
 	})
 }
 
@@ -78,7 +78,7 @@ func (s *DocumentService) GetByUserID(c *fiber.Ctx) error {
 	return c.JSON(model.PaginatedResponse{
 		Success:    true,
 		Message:    "Documents retrieved successfully",
-		Data:       documents,
+		Data:       helper.MaskSensitive(c, documents),
 		Pagination: pagination,
 	})
 }
@@ -114,7 +114,7 @@ func (s *DocumentService) Create(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(model.Response{
 		Success: true,
 		Message: "Document created successfully",
-		Data:    document,
+		Data:    helper.MaskSensitive(c, document),
 	})
 }
 
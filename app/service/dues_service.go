@@ -0,0 +1,510 @@
+package service
+
+import (
+	"errors"
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/helper"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DuesService struct {
+	duesRepo *repository.DuesRepository
+	userRepo *repository.UserRepository
+}
+
+func NewDuesService() *DuesService {
+	return &DuesService{
+		duesRepo: repository.NewDuesRepository(),
+		userRepo: repository.NewUserRepository(),
+	}
+}
+
+var paymentMethods = map[string]bool{
+	model.PaymentMethodCash:     true,
+	model.PaymentMethodTransfer: true,
+	model.PaymentMethodQRIS:     true,
+}
+
+func (s *DuesService) GetSchedules(c *fiber.Ctx) error {
+	activeOnly := c.Query("active", "false") == "true"
+	schedules, err := s.duesRepo.GetSchedules(activeOnly)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Dues schedules retrieved successfully",
+		Data:    schedules,
+	})
+}
+
+func (s *DuesService) CreateSchedule(c *fiber.Ctx) error {
+	var req model.CreateDuesScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	schedule := &model.DuesSchedule{IsActive: true}
+	if err := applyDuesSchedule(schedule, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	if err := s.duesRepo.CreateSchedule(schedule); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.Response{
+		Success: true,
+		Message: "Dues schedule created successfully",
+		Data:    schedule,
+	})
+}
+
+func (s *DuesService) UpdateSchedule(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	var req model.CreateDuesScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	schedule, err := s.duesRepo.GetScheduleByID(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Dues schedule not found",
+		})
+	}
+
+	if err := applyDuesSchedule(schedule, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	updateData := &model.DuesSchedule{
+		Name:      schedule.Name,
+		Amount:    schedule.Amount,
+		Frequency: schedule.Frequency,
+		VillageID: schedule.VillageID,
+		StartDate: schedule.StartDate,
+		EndDate:   schedule.EndDate,
+		IsActive:  schedule.IsActive,
+	}
+	if err := s.duesRepo.UpdateSchedule(uint(id), updateData); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	// Updates skips false, so deactivation is written separately
+	if !schedule.IsActive {
+		s.duesRepo.DeactivateSchedule(uint(id))
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Dues schedule updated successfully",
+		Data:    schedule,
+	})
+}
+
+func (s *DuesService) DeleteSchedule(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+
+	if _, err := s.duesRepo.GetScheduleByID(uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Dues schedule not found",
+		})
+	}
+
+	payments, err := s.duesRepo.CountPayments(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if payments > 0 {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: "Schedule has payments recorded, deactivate it instead",
+		})
+	}
+
+	if err := s.duesRepo.DeleteSchedule(uint(id)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Dues schedule deleted successfully",
+	})
+}
+
+func (s *DuesService) GetPayments(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var villageID *uint
+	if villageIDStr := c.Query("village_id"); villageIDStr != "" {
+		id, _ := strconv.ParseUint(villageIDStr, 10, 32)
+		v := uint(id)
+		villageID = &v
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	payments, total, err := s.duesRepo.GetPayments(limit, offset, c.Query("user_id"), villageID, from, to)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	pagination := helper.CreatePagination(int64(page), int64(limit), total)
+
+	return c.JSON(model.PaginatedResponse{
+		Success:    true,
+		Message:    "Dues payments retrieved successfully",
+		Data:       helper.MaskSensitive(c, payments),
+		Pagination: pagination,
+	})
+}
+
+func (s *DuesService) CreatePayment(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(string)
+	var req model.CreateDuesPaymentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if !paymentMethods[req.Method] {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid payment method, must be one of cash, transfer, qris",
+		})
+	}
+
+	if strings.TrimSpace(req.ReceiptNumber) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Receipt number is required",
+		})
+	}
+
+	user, err := s.userRepo.GetByID(req.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "User not found",
+		})
+	}
+
+	var schedule *model.DuesSchedule
+	if req.ScheduleID != nil {
+		schedule, err = s.duesRepo.GetScheduleByID(*req.ScheduleID)
+		if err == nil && !schedule.AppliesTo(user.VillageID, time.Now()) {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Dues schedule is not active for this member's village today",
+			})
+		}
+	} else {
+		schedule, err = s.duesRepo.GetScheduleForVillage(user.VillageID, time.Now())
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "No dues schedule applies to this member",
+		})
+	}
+
+	periods := 1
+	if req.Periods != nil && *req.Periods > 0 {
+		periods = *req.Periods
+	}
+
+	amount := schedule.Amount * int64(periods)
+	if req.Amount != nil {
+		if *req.Amount <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Amount must be positive",
+			})
+		}
+		amount = *req.Amount
+	}
+
+	paidAt := time.Now()
+	if req.PaidAt != nil && *req.PaidAt != "" {
+		date, err := helper.ParseDate(*req.PaidAt)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		paidAt = *date
+	}
+
+	payment := &model.DuesPayment{
+		UserID:        user.ID,
+		ScheduleID:    schedule.ID,
+		Periods:       periods,
+		Amount:        amount,
+		Method:        req.Method,
+		ReceiptNumber: strings.TrimSpace(req.ReceiptNumber),
+		PaidAt:        paidAt,
+		RecordedBy:    &adminID,
+		Note:          req.Note,
+	}
+
+	if err := s.duesRepo.CreatePayment(payment); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.Response{
+		Success: true,
+		Message: "Dues payment recorded successfully",
+		Data:    helper.MaskSensitive(c, payment),
+	})
+}
+
+func (s *DuesService) GetArrears(c *fiber.Ctx) error {
+	arrears, err := s.calculateArrears(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Dues arrears retrieved successfully",
+		Data:    helper.MaskSensitive(c, arrears),
+	})
+}
+
+func (s *DuesService) GetVillageSummary(c *fiber.Ctx) error {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	// Default to the current month
+	now := time.Now()
+	if from == nil {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		from = &start
+	}
+	if to == nil {
+		end := from.AddDate(0, 1, 0)
+		to = &end
+	}
+
+	summary, err := s.duesRepo.GetVillageSummary(*from, *to)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Dues summary retrieved successfully",
+		Data:    summary,
+	})
+}
+
+func (s *DuesService) GetMyDues(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	payments, total, err := s.duesRepo.GetPayments(limit, offset, userID, nil, nil, nil)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	// A member without an applicable schedule simply has no arrears
+	arrears, _ := s.calculateArrears(userID)
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Dues history retrieved successfully",
+		Data: fiber.Map{
+			"payments":   payments,
+			"pagination": helper.CreatePagination(int64(page), int64(limit), total),
+			"arrears":    arrears,
+		},
+	})
+}
+
+// calculateArrears compares the periods due since the member joined (or
+// the schedule started, whichever is later) with the periods paid.
+func (s *DuesService) calculateArrears(userID string) (*model.DuesArrears, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	schedule, err := s.duesRepo.GetScheduleForVillage(user.VillageID, time.Now())
+	if err != nil {
+		return nil, errors.New("no dues schedule applies to this member")
+	}
+
+	paid, err := s.duesRepo.GetPaidPeriods(user.ID, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := schedule.StartDate
+	if user.CreatedAt.After(start) {
+		start = user.CreatedAt
+	}
+	end := time.Now()
+	if schedule.EndDate != nil && schedule.EndDate.Before(end) {
+		end = *schedule.EndDate
+	}
+
+	due := countDuesPeriods(schedule.Frequency, start, end)
+	unpaid := due - paid
+	if unpaid < 0 {
+		unpaid = 0
+	}
+
+	return &model.DuesArrears{
+		UserID:        user.ID,
+		Schedule:      schedule,
+		DuePeriods:    due,
+		PaidPeriods:   paid,
+		UnpaidPeriods: unpaid,
+		AmountDue:     int64(unpaid) * schedule.Amount,
+	}, nil
+}
+
+// countDuesPeriods counts the calendar months (or years) from start to end,
+// both inclusive.
+func countDuesPeriods(frequency string, start, end time.Time) int {
+	if end.Before(start) {
+		return 0
+	}
+	if frequency == model.DuesFrequencyYearly {
+		return end.Year() - start.Year() + 1
+	}
+	return (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month()) + 1
+}
+
+func applyDuesSchedule(schedule *model.DuesSchedule, req *model.CreateDuesScheduleRequest) error {
+	if req.Name != "" {
+		schedule.Name = req.Name
+	}
+	if req.Amount != 0 {
+		if req.Amount < 0 {
+			return errors.New("amount must be positive")
+		}
+		schedule.Amount = req.Amount
+	}
+	if req.Frequency != nil {
+		if *req.Frequency != model.DuesFrequencyMonthly && *req.Frequency != model.DuesFrequencyYearly {
+			return errors.New("frequency must be monthly or yearly")
+		}
+		schedule.Frequency = *req.Frequency
+	}
+	if schedule.Frequency == "" {
+		schedule.Frequency = model.DuesFrequencyMonthly
+	}
+	schedule.VillageID = helper.GetUintPointer(req.VillageID, schedule.VillageID)
+	if req.StartDate != "" {
+		startDate, err := helper.ParseDate(req.StartDate)
+		if err != nil {
+			return err
+		}
+		schedule.StartDate = *startDate
+	}
+	if req.EndDate != nil && *req.EndDate != "" {
+		endDate, err := helper.ParseDate(*req.EndDate)
+		if err != nil {
+			return err
+		}
+		schedule.EndDate = endDate
+	}
+	schedule.IsActive = helper.GetBoolValue(req.IsActive, schedule.IsActive)
+
+	if schedule.Name == "" || schedule.Amount == 0 || schedule.StartDate.IsZero() {
+		return errors.New("name, amount and start_date are required")
+	}
+	return nil
+}
+
+// parseDateRange reads the optional from and to query dates. The to date
+// is inclusive, so the returned upper bound is the start of the next day.
+func parseDateRange(c *fiber.Ctx) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+	if value := c.Query("from"); value != "" {
+		date, err := helper.ParseDate(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		from = date
+	}
+	if value := c.Query("to"); value != "" {
+		date, err := helper.ParseDate(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		next := date.AddDate(0, 0, 1)
+		to = &next
+	}
+	return from, to, nil
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/helper"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HouseholdService struct {
+	householdRepo *repository.HouseholdRepository
+	userRepo      *repository.UserRepository
+}
+
+func NewHouseholdService() *HouseholdService {
+	return &HouseholdService{
+		householdRepo: repository.NewHouseholdRepository(),
+		userRepo:      repository.NewUserRepository(),
+	}
+}
+
+var householdRelations = map[string]bool{
+	model.HouseholdRelationHead:   true,
+	model.HouseholdRelationSpouse: true,
+	model.HouseholdRelationChild:  true,
+	model.HouseholdRelationParent: true,
+	model.HouseholdRelationOther:  true,
+}
+
+func (s *HouseholdService) GetAll(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var villageID *uint
+	if villageIDStr := c.Query("village_id"); villageIDStr != "" {
+		id, _ := strconv.ParseUint(villageIDStr, 10, 32)
+		v := uint(id)
+		villageID = &v
+	}
+
+	households, total, err := s.householdRepo.GetAll(limit, offset, villageID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	pagination := helper.CreatePagination(int64(page), int64(limit), total)
+
+	return c.JSON(model.PaginatedResponse{
+		Success:    true,
+		Message:    "Households retrieved successfully",
+		Data:       helper.MaskSensitive(c, households),
+		Pagination: pagination,
+	})
+}
+
+// GetByID returns the household to admins and to its own members.
+func (s *HouseholdService) GetByID(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+
+	if !helper.IsStaff(c) {
+		caller, err := s.userRepo.GetByID(c.Locals("user_id").(string))
+		if err != nil || caller.HouseholdID == nil || *caller.HouseholdID != uint(id) {
+			return c.Status(fiber.StatusForbidden).JSON(model.Response{
+				Success: false,
+				Message: "You do not have access to this household",
+			})
+		}
+	}
+
+	household, err := s.householdRepo.GetByID(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Household not found",
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Household retrieved successfully",
+		Data:    helper.MaskSensitive(c, household),
+	})
+}
+
+func (s *HouseholdService) GetByKKNumber(c *fiber.Ctx) error {
+	household, err := s.householdRepo.GetByKKNumber(c.Params("kk"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Household not found",
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Household retrieved successfully",
+		Data:    helper.MaskSensitive(c, household),
+	})
+}
+
+func (s *HouseholdService) Create(c *fiber.Ctx) error {
+	var req model.CreateHouseholdRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	req.KKNumber = strings.TrimSpace(req.KKNumber)
+	if req.KKNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "KK number is required",
+		})
+	}
+
+	household := &model.Household{
+		KKNumber:  req.KKNumber,
+		VillageID: req.VillageID,
+		Address:   req.Address,
+	}
+
+	if err := s.householdRepo.Create(household); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.Response{
+		Success: true,
+		Message: "Household created successfully",
+		Data:    household,
+	})
+}
+
+func (s *HouseholdService) Update(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	var req model.CreateHouseholdRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	existing, err := s.householdRepo.GetByID(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Household not found",
+		})
+	}
+
+	kkNumber := existing.KKNumber
+	if trimmed := strings.TrimSpace(req.KKNumber); trimmed != "" {
+		kkNumber = trimmed
+	}
+
+	updateData := &model.Household{
+		KKNumber:  kkNumber,
+		VillageID: helper.GetUintPointer(req.VillageID, existing.VillageID),
+		Address:   helper.GetStringPointer(req.Address, existing.Address),
+	}
+
+	if err := s.householdRepo.Update(uint(id), updateData); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	household, _ := s.householdRepo.GetByID(uint(id))
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Household updated successfully",
+		Data:    helper.MaskSensitive(c, household),
+	})
+}
+
+func (s *HouseholdService) Delete(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+
+	if _, err := s.householdRepo.GetByID(uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Household not found",
+		})
+	}
+
+	if err := s.householdRepo.Delete(uint(id)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Household deleted successfully",
+	})
+}
+
+func (s *HouseholdService) AddMember(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	var req model.HouseholdMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if !householdRelations[req.Relation] {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid relation, must be one of head, spouse, child, parent, other",
+		})
+	}
+
+	if _, err := s.householdRepo.GetByID(uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Household not found",
+		})
+	}
+
+	if _, err := s.userRepo.GetByID(req.UserID); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "User not found",
+		})
+	}
+
+	if req.Relation == model.HouseholdRelationHead {
+		heads, err := s.householdRepo.CountHeads(uint(id), req.UserID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		if heads > 0 {
+			return c.Status(fiber.StatusConflict).JSON(model.Response{
+				Success: false,
+				Message: "Household already has a head of family",
+			})
+		}
+	}
+
+	if err := s.householdRepo.SetMember(uint(id), req.UserID, req.Relation); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	household, _ := s.householdRepo.GetByID(uint(id))
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Household member saved successfully",
+		Data:    helper.MaskSensitive(c, household),
+	})
+}
+
+func (s *HouseholdService) RemoveMember(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+
+	if err := s.householdRepo.RemoveMember(uint(id), c.Params("userId")); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "User is not a member of this household",
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Household member removed successfully",
+	})
+}
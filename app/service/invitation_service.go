@@ -0,0 +1,220 @@
+package service
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/helper"
+	"arek-muhammadiyah-be/helper/utils"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type InvitationService struct {
+	invitationRepo *repository.InvitationRepository
+	userRepo       *repository.UserRepository
+}
+
+func NewInvitationService() *InvitationService {
+	return &InvitationService{
+		invitationRepo: repository.NewInvitationRepository(),
+		userRepo:       repository.NewUserRepository(),
+	}
+}
+
+// sendInvitation issues a fresh token for the member, reusing their open
+// invitation if there is one so the previous link stops working.
+func sendInvitation(invitationRepo *repository.InvitationRepository, userID, sentBy string) (fiber.Map, error) {
+	now := time.Now()
+	token, hash := helper.GenerateInvitationToken()
+	expiresAt := helper.InvitationExpiry(now)
+
+	if open, err := invitationRepo.GetOpenByUserID(userID); err == nil {
+		if err := invitationRepo.Resend(open.ID, hash, now, expiresAt); err != nil {
+			return nil, err
+		}
+	} else {
+		invitation := &model.Invitation{
+			UserID:     userID,
+			TokenHash:  hash,
+			ExpiresAt:  expiresAt,
+			CreatedBy:  &sentBy,
+			LastSentAt: now,
+		}
+		if err := invitationRepo.Create(invitation); err != nil {
+			return nil, err
+		}
+	}
+
+	return fiber.Map{
+		"token":      token,
+		"link":       helper.InvitationLink(token),
+		"expires_at": expiresAt,
+	}, nil
+}
+
+func (s *InvitationService) GetPending(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var villageID *uint
+	if villageIDStr := c.Query("village_id"); villageIDStr != "" {
+		id, _ := strconv.ParseUint(villageIDStr, 10, 32)
+		v := uint(id)
+		villageID = &v
+	}
+
+	invitations, total, err := s.invitationRepo.GetPending(limit, offset, villageID, time.Now())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	pagination := helper.CreatePagination(int64(page), int64(limit), total)
+
+	return c.JSON(model.PaginatedResponse{
+		Success:    true,
+		Message:    "Pending invitations retrieved successfully",
+		Data:       helper.MaskSensitive(c, invitations),
+		Pagination: pagination,
+	})
+}
+
+// Send invites a member who has no password yet, or resends their
+// invitation with a new link and expiry.
+func (s *InvitationService) Send(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "User not found",
+		})
+	}
+
+	if user.Password != "" {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: "Account is already activated",
+		})
+	}
+
+	invitation, err := sendInvitation(s.invitationRepo, user.ID, c.Locals("user_id").(string))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Invitation sent successfully",
+		Data:    invitation,
+	})
+}
+
+func (s *InvitationService) Revoke(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	invitation, err := s.invitationRepo.GetByID(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Invitation not found",
+		})
+	}
+
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: "Invitation is already closed",
+		})
+	}
+
+	if err := s.invitationRepo.Revoke(invitation.ID, time.Now()); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Invitation revoked successfully",
+	})
+}
+
+// Check lets the set-password page show who the invitation is for before
+// the member submits a password.
+func (s *InvitationService) Check(c *fiber.Ctx) error {
+	invitation, err := s.invitationRepo.GetByTokenHash(helper.HashInvitationToken(c.Params("token")))
+	if err != nil || !invitation.IsPending(time.Now()) {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Invitation is invalid or has expired",
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Invitation is valid",
+		Data: fiber.Map{
+			"user_id":    invitation.UserID,
+			"name":       invitation.User.Name,
+			"expires_at": invitation.ExpiresAt,
+		},
+	})
+}
+
+func (s *InvitationService) Accept(c *fiber.Ctx) error {
+	var req model.AcceptInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if len(req.Password) < 6 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Password must be at least 6 characters",
+		})
+	}
+
+	now := time.Now()
+	invitation, err := s.invitationRepo.GetByTokenHash(helper.HashInvitationToken(req.Token))
+	if err != nil || !invitation.IsPending(now) {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invitation is invalid or has expired",
+		})
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: "Failed to hash password",
+		})
+	}
+
+	if err := s.invitationRepo.Accept(invitation, hashedPassword, now); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Account activated successfully",
+		Data: fiber.Map{
+			"user_id": invitation.UserID,
+		},
+	})
+}
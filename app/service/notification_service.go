@@ -0,0 +1,76 @@
+package service
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/config"
+	"arek-muhammadiyah-be/helper"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationService struct {
+	notificationRepo *repository.NotificationRepository
+}
+
+func NewNotificationService() *NotificationService {
+	return &NotificationService{
+		notificationRepo: repository.NewNotificationRepository(),
+	}
+}
+
+// Notify stores an in-app notification for a member. Failures are logged
+// rather than returned so they never break the action that triggered them.
+func (s *NotificationService) Notify(userID, notificationType, title, message string) {
+	err := s.notificationRepo.Create(&model.Notification{
+		UserID:  userID,
+		Type:    notificationType,
+		Title:   title,
+		Message: message,
+	})
+	if err != nil && config.Logger != nil {
+		config.Logger.Printf("notify %s: %v", userID, err)
+	}
+}
+
+func (s *NotificationService) GetMine(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	notifications, total, err := s.notificationRepo.GetByUserID(userID, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	pagination := helper.CreatePagination(int64(page), int64(limit), total)
+
+	return c.JSON(model.PaginatedResponse{
+		Success:    true,
+		Message:    "Notifications retrieved successfully",
+		Data:       notifications,
+		Pagination: pagination,
+	})
+}
+
+func (s *NotificationService) MarkRead(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+
+	if err := s.notificationRepo.MarkRead(uint(id), userID); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Notification not found",
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Notification marked as read",
+	})
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/helper"
+	"arek-muhammadiyah-be/helper/storage"
+	"mime/multipart"
+	"regexp"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// photoDir keys a member's photo folder on a hash of their ID, so IDs that
+// differ only in punctuation do not share a folder.
+func photoDir(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return "photos/" + hex.EncodeToString(sum[:16])
+}
+
+// uploadUserPhoto handles the "photo" form file for both the self-service
+// and admin endpoints.
+func uploadUserPhoto(c *fiber.Ctx, userRepo *repository.UserRepository, userID string) error {
+	if _, err := userRepo.GetByID(userID); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "User not found",
+		})
+	}
+
+	file, err := c.FormFile("photo")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Photo file required",
+		})
+	}
+
+	user, err := saveUserPhoto(userRepo, storage.NewStorage(), userID, file)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Photo uploaded successfully",
+		Data:    helper.MaskSensitive(c, user),
+	})
+}
+
+func saveUserPhoto(userRepo *repository.UserRepository, store storage.Storage, userID string, file *multipart.FileHeader) (*model.User, error) {
+	if file.Size > helper.MaxPhotoSize {
+		return nil, errors.New("photo must be at most 8 MB")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, errors.New("failed to open photo")
+	}
+	defer src.Close()
+
+	card, thumb, err := helper.ProcessPhoto(src)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Uploads are served without authentication, so every upload gets a
+	// random name that cannot be guessed from the member ID
+	dir := photoDir(userID) + "/" + helper.GenerateRandomString(24)
+	cardURL, err := store.Save(dir+"-card.jpg", card)
+	if err != nil {
+		return nil, err
+	}
+	thumbURL, err := store.Save(dir+"-thumb.jpg", thumb)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := userRepo.Update(userID, &model.User{PhotoURL: &cardURL, PhotoThumbURL: &thumbURL}); err != nil {
+		return nil, err
+	}
+
+	deletePhotos(store, previous)
+	return userRepo.GetByID(userID)
+}
+
+// deletePhotos removes the stored files behind the user's photo URLs.
+func deletePhotos(store storage.Storage, user *model.User) {
+	for _, url := range []*string{user.PhotoURL, user.PhotoThumbURL} {
+		if url == nil {
+			continue
+		}
+		if path, ok := store.PathOf(*url); ok {
+			store.Delete(path)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/helper"
+	"arek-muhammadiyah-be/helper/storage"
+	"arek-muhammadiyah-be/helper/utils"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PrivacyService struct {
+	userRepo     *repository.UserRepository
+	ticketRepo   *repository.TicketRepository
+	documentRepo *repository.DocumentRepository
+	articleRepo  *repository.ArticleRepository
+	duesRepo     *repository.DuesRepository
+	erasureRepo  *repository.ErasureRepository
+}
+
+func NewPrivacyService() *PrivacyService {
+	return &PrivacyService{
+		userRepo:     repository.NewUserRepository(),
+		ticketRepo:   repository.NewTicketRepository(),
+		documentRepo: repository.NewDocumentRepository(),
+		articleRepo:  repository.NewArticleRepository(),
+		duesRepo:     repository.NewDuesRepository(),
+		erasureRepo:  repository.NewErasureRepository(),
+	}
+}
+
+func (s *PrivacyService) ExportMe(c *fiber.Ctx) error {
+	return s.export(c, c.Locals("user_id").(string))
+}
+
+func (s *PrivacyService) ExportUser(c *fiber.Ctx) error {
+	return s.export(c, c.Params("id"))
+}
+
+// export sends a zip archive with one JSON file per kind of data held
+// about the member.
+func (s *PrivacyService) export(c *fiber.Ctx, userID string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "User not found",
+		})
+	}
+
+	tickets, _, err := s.ticketRepo.GetByUserID(userID, -1, 0)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	ticketMessages, err := s.ticketRepo.GetMessagesForExport(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	documents, _, err := s.documentRepo.GetByUserID(userID, -1, 0)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	articles, err := s.articleRepo.GetByUserID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	payments, _, err := s.duesRepo.GetPayments(-1, 0, userID, nil, nil, nil)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	cardHistory, err := s.userRepo.GetCardStatusHistory(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", user},
+		{"tickets.json", tickets},
+		{"ticket_messages.json", ticketMessages},
+		{"documents.json", documents},
+		{"articles.json", articles},
+		{"dues_payments.json", payments},
+		{"card_history.json", cardHistory},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		w.Write(content)
+	}
+	if err := archive.Close(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	filename := fmt.Sprintf("data-%s-%s.zip", unsafePathChars.ReplaceAllString(userID, "_"), time.Now().Format("20060102"))
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	return c.Send(buf.Bytes())
+}
+
+func (s *PrivacyService) RequestErasure(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	var req model.CreateErasureRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	if s.erasureRepo.HasPending(userID) {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: "An erasure request is already pending",
+		})
+	}
+
+	request := &model.ErasureRequest{
+		UserID: userID,
+		Reason: req.Reason,
+		Status: model.ChangeRequestPending,
+	}
+	if err := s.erasureRepo.Create(request); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.Response{
+		Success: true,
+		Message: "Erasure requested successfully",
+		Data:    request,
+	})
+}
+
+func (s *PrivacyService) GetMyErasureRequests(c *fiber.Ctx) error {
+	requests, err := s.erasureRepo.GetByUserID(c.Locals("user_id").(string))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Erasure requests retrieved successfully",
+		Data:    requests,
+	})
+}
+
+func (s *PrivacyService) GetErasureRequests(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	status := c.Query("status", model.ChangeRequestPending)
+	offset := (page - 1) * limit
+
+	requests, total, err := s.erasureRepo.GetAll(limit, offset, status)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	pagination := helper.CreatePagination(int64(page), int64(limit), total)
+
+	return c.JSON(model.PaginatedResponse{
+		Success:    true,
+		Message:    "Erasure requests retrieved successfully",
+		Data:       helper.MaskSensitive(c, requests),
+		Pagination: pagination,
+	})
+}
+
+func (s *PrivacyService) ApproveErasure(c *fiber.Ctx) error {
+	return s.reviewErasure(c, true)
+}
+
+func (s *PrivacyService) RejectErasure(c *fiber.Ctx) error {
+	return s.reviewErasure(c, false)
+}
+
+func (s *PrivacyService) reviewErasure(c *fiber.Ctx, approve bool) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	reviewerID := c.Locals("user_id").(string)
+
+	var req model.ReviewChangeRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	request, err := s.erasureRepo.GetByID(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Erasure request not found",
+		})
+	}
+
+	if request.Status != model.ChangeRequestPending {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: "Erasure request has already been reviewed",
+		})
+	}
+
+	if approve {
+		// Nobody knows this password, which locks the anonymized account
+		passwordHash, hashErr := utils.HashPassword(helper.GenerateRandomString(32))
+		if hashErr != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: "Failed to hash password",
+			})
+		}
+		var filePaths []string
+		filePaths, err = s.erasureRepo.Approve(request, passwordHash, reviewerID, req.Note)
+		if err == nil {
+			store := storage.NewStorage()
+			if request.User != nil {
+				deletePhotos(store, request.User)
+			}
+			for _, filePath := range filePaths {
+				if path, ok := store.PathOf(filePath); ok {
+					store.Delete(path)
+				}
+			}
+		}
+	} else {
+		err = s.erasureRepo.Reject(request.ID, reviewerID, req.Note)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	request, _ = s.erasureRepo.GetByID(uint(id))
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Erasure request reviewed successfully",
+		Data:    helper.MaskSensitive(c, request),
+	})
+}
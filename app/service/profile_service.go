@@ -0,0 +1,223 @@
+package service
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/helper"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProfileService struct {
+	userRepo          *repository.UserRepository
+	profileChangeRepo *repository.ProfileChangeRepository
+}
+
+func NewProfileService() *ProfileService {
+	return &ProfileService{
+		userRepo:          repository.NewUserRepository(),
+		profileChangeRepo: repository.NewProfileChangeRepository(),
+	}
+}
+
+func (s *ProfileService) GetMe(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "User not found",
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Profile retrieved successfully",
+		Data:    helper.MaskSensitive(c, user),
+	})
+}
+
+func (s *ProfileService) UpdateMe(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	var req model.UpdateMeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	existing, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "User not found",
+		})
+	}
+
+	updateData := &model.User{
+		Name:    helper.GetStringValue(req.Name, existing.Name),
+		Telp:    helper.GetStringPointer(req.Telp, existing.Telp),
+		Address: helper.GetStringPointer(req.Address, existing.Address),
+	}
+
+	profile := &model.UserProfileRequest{
+		BirthPlace:    req.BirthPlace,
+		BirthDate:     req.BirthDate,
+		Gender:        req.Gender,
+		Occupation:    req.Occupation,
+		Education:     req.Education,
+		MaritalStatus: req.MaritalStatus,
+	}
+	if err := helper.ApplyUserProfile(updateData, profile); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	if err := s.userRepo.Update(userID, updateData); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	// NIK and village changes wait for admin approval
+	var changeRequest *model.ProfileChangeRequest
+	nikChanged := req.NIK != nil && (existing.NIK == nil || *req.NIK != *existing.NIK)
+	villageChanged := req.VillageID != nil && (existing.VillageID == nil || *req.VillageID != *existing.VillageID)
+	if nikChanged || villageChanged {
+		changeRequest = &model.ProfileChangeRequest{
+			UserID: userID,
+			Reason: req.Reason,
+			Status: model.ChangeRequestPending,
+		}
+		if nikChanged {
+			changeRequest.NIK = req.NIK
+		}
+		if villageChanged {
+			changeRequest.VillageID = req.VillageID
+		}
+
+		if err := s.profileChangeRepo.Create(changeRequest); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+	}
+
+	user, _ := s.userRepo.GetByID(userID)
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Profile updated successfully",
+		Data: fiber.Map{
+			"user":           user,
+			"change_request": changeRequest,
+		},
+	})
+}
+
+func (s *ProfileService) UploadMyPhoto(c *fiber.Ctx) error {
+	return uploadUserPhoto(c, s.userRepo, c.Locals("user_id").(string))
+}
+
+func (s *ProfileService) GetMyChangeRequests(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	requests, err := s.profileChangeRepo.GetByUserID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Change requests retrieved successfully",
+		Data:    helper.MaskSensitive(c, requests),
+	})
+}
+
+func (s *ProfileService) GetChangeRequests(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	status := c.Query("status", model.ChangeRequestPending)
+	offset := (page - 1) * limit
+
+	requests, total, err := s.profileChangeRepo.GetAll(limit, offset, status)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	pagination := helper.CreatePagination(int64(page), int64(limit), total)
+
+	return c.JSON(model.PaginatedResponse{
+		Success:    true,
+		Message:    "Change requests retrieved successfully",
+		Data:       helper.MaskSensitive(c, requests),
+		Pagination: pagination,
+	})
+}
+
+func (s *ProfileService) ApproveChangeRequest(c *fiber.Ctx) error {
+	return s.reviewChangeRequest(c, true)
+}
+
+func (s *ProfileService) RejectChangeRequest(c *fiber.Ctx) error {
+	return s.reviewChangeRequest(c, false)
+}
+
+func (s *ProfileService) reviewChangeRequest(c *fiber.Ctx, approve bool) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	reviewerID := c.Locals("user_id").(string)
+
+	var req model.ReviewChangeRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	request, err := s.profileChangeRepo.GetByID(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Change request not found",
+		})
+	}
+
+	if request.Status != model.ChangeRequestPending {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: "Change request has already been reviewed",
+		})
+	}
+
+	if approve {
+		err = s.profileChangeRepo.Approve(request, reviewerID, req.Note)
+	} else {
+		err = s.profileChangeRepo.Reject(request.ID, reviewerID, req.Note)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	request, _ = s.profileChangeRepo.GetByID(uint(id))
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Change request reviewed successfully",
+		Data:    helper.MaskSensitive(c, request),
+	})
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"errors"
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/helper"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReferralService struct {
+	userRepo *repository.UserRepository
+}
+
+func NewReferralService() *ReferralService {
+	return &ReferralService{
+		userRepo: repository.NewUserRepository(),
+	}
+}
+
+// resolveReferrer returns the recruiter's member ID from either the
+// referred_by ID or the referral_code on a create or register request.
+func resolveReferrer(userRepo *repository.UserRepository, req *model.CreateUserRequest) (*string, error) {
+	if req.ReferredBy != nil && *req.ReferredBy != "" {
+		referrer, err := userRepo.GetByID(*req.ReferredBy)
+		if err != nil {
+			return nil, errors.New("referrer not found")
+		}
+		return &referrer.ID, nil
+	}
+
+	if req.ReferralCode != nil && *req.ReferralCode != "" {
+		code := strings.ToUpper(strings.TrimSpace(*req.ReferralCode))
+		referrer, err := userRepo.GetByReferralCode(code)
+		if err != nil {
+			return nil, errors.New("invalid referral code")
+		}
+		return &referrer.ID, nil
+	}
+
+	return nil, nil
+}
+
+// newReferralCode picks a referral code that no member has used yet.
+func newReferralCode(userRepo *repository.UserRepository) *string {
+	for i := 0; i < 5; i++ {
+		code := helper.GenerateReferralCode()
+		if !userRepo.ReferralCodeExists(code) {
+			return &code
+		}
+	}
+	return nil
+}
+
+func (s *ReferralService) GetMyCode(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "User not found",
+		})
+	}
+
+	// Members created before referral codes existed get one on first use
+	if user.ReferralCode == nil {
+		code := newReferralCode(s.userRepo)
+		if code == nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: "Failed to generate referral code",
+			})
+		}
+		if err := s.userRepo.Update(userID, &model.User{ReferralCode: code}); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		user.ReferralCode = code
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Referral code retrieved successfully",
+		Data: fiber.Map{
+			"referral_code": user.ReferralCode,
+		},
+	})
+}
+
+func (s *ReferralService) GetMyReferrals(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	users, total, err := s.userRepo.GetReferrals(userID, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	pagination := helper.CreatePagination(int64(page), int64(limit), total)
+
+	return c.JSON(model.PaginatedResponse{
+		Success:    true,
+		Message:    "Referred members retrieved successfully",
+		Data:       helper.MaskSensitive(c, users),
+		Pagination: pagination,
+	})
+}
+
+func (s *ReferralService) GetLeaderboard(c *fiber.Ctx) error {
+	from, to, err := leaderboardRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	var villageID *uint
+	if villageIDStr := c.Query("village_id"); villageIDStr != "" {
+		id, _ := strconv.ParseUint(villageIDStr, 10, 32)
+		v := uint(id)
+		villageID = &v
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	entries, err := s.userRepo.ReferralLeaderboard(from, to, villageID, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Referral leaderboard retrieved successfully",
+		Data:    entries,
+	})
+}
+
+func (s *ReferralService) GetVillageLeaderboard(c *fiber.Ctx) error {
+	from, to, err := leaderboardRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	entries, err := s.userRepo.VillageReferralLeaderboard(from, to)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Village referral leaderboard retrieved successfully",
+		Data:    entries,
+	})
+}
+
+// leaderboardRange defaults to the current year up to today.
+func leaderboardRange(c *fiber.Ctx) (time.Time, time.Time, error) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	now := time.Now()
+	if from == nil {
+		start := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+		from = &start
+	}
+	if to == nil {
+		end := now.AddDate(0, 0, 1)
+		to = &end
+	}
+	return *from, *to, nil
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"errors"
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/helper"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RegionService struct {
+	regionRepo *repository.RegionRepository
+}
+
+func NewRegionService() *RegionService {
+	return &RegionService{
+		regionRepo: repository.NewRegionRepository(),
+	}
+}
+
+// GetTree returns every province with its regencies, districts and active
+// villages nested inside.
+func (s *RegionService) GetTree(c *fiber.Ctx) error {
+	provinces, err := s.regionRepo.GetTree()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Region tree retrieved successfully",
+		Data:    provinces,
+	})
+}
+
+func (s *RegionService) GetProvinces(c *fiber.Ctx) error {
+	provinces, err := s.regionRepo.GetProvinces()
+	return s.list(c, "Provinces", provinces, err)
+}
+
+func (s *RegionService) GetRegencies(c *fiber.Ctx) error {
+	regencies, err := s.regionRepo.GetRegencies(c.Query("province_code"))
+	return s.list(c, "Regencies", regencies, err)
+}
+
+func (s *RegionService) GetDistricts(c *fiber.Ctx) error {
+	districts, err := s.regionRepo.GetDistricts(c.Query("regency_code"))
+	return s.list(c, "Districts", districts, err)
+}
+
+func (s *RegionService) list(c *fiber.Ctx, name string, data interface{}, err error) error {
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: name + " retrieved successfully",
+		Data:    data,
+	})
+}
+
+func (s *RegionService) CreateProvince(c *fiber.Ctx) error {
+	var req model.CreateRegionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if err := helper.ValidateRegionCode(model.RegionLevelProvince, req.Code, ""); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	province := &model.Province{Code: req.Code, Name: req.Name}
+	return s.create(c, "Province", province)
+}
+
+func (s *RegionService) CreateRegency(c *fiber.Ctx) error {
+	var req model.CreateRegionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if err := helper.ValidateRegionCode(model.RegionLevelRegency, req.Code, req.ParentCode); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if _, err := s.regionRepo.GetProvince(req.ParentCode); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Province not found",
+		})
+	}
+
+	regencyType, err := regencyType(req.Type, model.RegencyTypeKabupaten)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	regency := &model.Regency{
+		Code:         req.Code,
+		ProvinceCode: req.ParentCode,
+		Name:         req.Name,
+		Type:         regencyType,
+	}
+	return s.create(c, "Regency", regency)
+}
+
+func (s *RegionService) CreateDistrict(c *fiber.Ctx) error {
+	var req model.CreateRegionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if err := helper.ValidateRegionCode(model.RegionLevelDistrict, req.Code, req.ParentCode); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if _, err := s.regionRepo.GetRegency(req.ParentCode); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Regency not found",
+		})
+	}
+
+	district := &model.District{
+		Code:        req.Code,
+		RegencyCode: req.ParentCode,
+		Name:        req.Name,
+	}
+	return s.create(c, "District", district)
+}
+
+func (s *RegionService) create(c *fiber.Ctx, name string, region interface{}) error {
+	if err := s.regionRepo.Create(region); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.Response{
+		Success: true,
+		Message: name + " created successfully",
+		Data:    region,
+	})
+}
+
+func (s *RegionService) UpdateProvince(c *fiber.Ctx) error {
+	return s.update(c, model.RegionLevelProvince)
+}
+
+func (s *RegionService) UpdateRegency(c *fiber.Ctx) error {
+	return s.update(c, model.RegionLevelRegency)
+}
+
+func (s *RegionService) UpdateDistrict(c *fiber.Ctx) error {
+	return s.update(c, model.RegionLevelDistrict)
+}
+
+// update only changes names, and the type of a regency. Codes identify
+// the region for everything underneath it and stay fixed.
+func (s *RegionService) update(c *fiber.Ctx, level string) error {
+	code := c.Params("code")
+	var req model.CreateRegionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	region, err := s.find(level, code)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Region not found",
+		})
+	}
+
+	columns := map[string]interface{}{}
+	if req.Name != "" {
+		columns["name"] = req.Name
+	}
+	if regency, ok := region.(*model.Regency); ok && req.Type != nil {
+		regencyType, err := regencyType(req.Type, regency.Type)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		columns["type"] = regencyType
+	}
+
+	if len(columns) > 0 {
+		if err := s.regionRepo.Rename(region, code, columns); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+	}
+
+	updated, _ := s.find(level, code)
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Region updated successfully",
+		Data:    updated,
+	})
+}
+
+func (s *RegionService) DeleteProvince(c *fiber.Ctx) error {
+	return s.delete(c, model.RegionLevelProvince)
+}
+
+func (s *RegionService) DeleteRegency(c *fiber.Ctx) error {
+	return s.delete(c, model.RegionLevelRegency)
+}
+
+func (s *RegionService) DeleteDistrict(c *fiber.Ctx) error {
+	return s.delete(c, model.RegionLevelDistrict)
+}
+
+func (s *RegionService) delete(c *fiber.Ctx, level string) error {
+	code := c.Params("code")
+	region, err := s.find(level, code)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Region not found",
+		})
+	}
+
+	children, err := s.regionRepo.CountChildren(level, code)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if children > 0 {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: "Region still has regions or villages under it, including villages in the trash",
+		})
+	}
+
+	if err := s.regionRepo.Delete(region, code); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Region deleted successfully",
+	})
+}
+
+func (s *RegionService) find(level, code string) (interface{}, error) {
+	switch level {
+	case model.RegionLevelProvince:
+		return s.regionRepo.GetProvince(code)
+	case model.RegionLevelRegency:
+		return s.regionRepo.GetRegency(code)
+	default:
+		return s.regionRepo.GetDistrict(code)
+	}
+}
+
+func regencyType(value *string, defaultVal string) (string, error) {
+	if value == nil {
+		return defaultVal, nil
+	}
+	switch t := strings.ToLower(strings.TrimSpace(*value)); t {
+	case model.RegencyTypeKabupaten, model.RegencyTypeKota:
+		return t, nil
+	}
+	return "", errors.New("type must be kabupaten or kota")
+}
@@ -4,23 +4,34 @@ import (
 	"errors"
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/config"
 	"arek-muhammadiyah-be/helper"
+	"fmt"
+	"strings"
 	"time"
 )
 
 type TicketService struct {
-	ticketRepo *repository.TicketRepository
+	ticketRepo      *repository.TicketRepository
+	userRepo        *repository.UserRepository
+	categoryRepo    *repository.CategoryRepository
+	villageRepo     *repository.VillageRepository
+	notificationSvc *NotificationService
 }
 
 func NewTicketService() *TicketService {
 	return &TicketService{
-		ticketRepo: repository.NewTicketRepository(),
+		ticketRepo:      repository.NewTicketRepository(),
+		userRepo:        repository.NewUserRepository(),
+		categoryRepo:    repository.NewCategoryRepository(),
+		villageRepo:     repository.NewVillageRepository(),
+		notificationSvc: NewNotificationService(),
 	}
 }
 
-func (s *TicketService) GetAllTickets(page, limit int, status *model.TicketStatus) ([]model.Ticket, model.Pagination, error) {
+func (s *TicketService) GetAllTickets(page, limit int, status *model.TicketStatus, region *model.RegionFilter) ([]model.Ticket, model.Pagination, error) {
 	offset := (page - 1) * limit
-	tickets, total, err := s.ticketRepo.GetAll(limit, offset, status)
+	tickets, total, err := s.ticketRepo.GetAll(limit, offset, status, region)
 	if err != nil {
 		return nil, model.Pagination{}, err
 	}
@@ -29,8 +40,16 @@ func (s *TicketService) GetAllTickets(page, limit int, status *model.TicketStatu
 	return tickets, pagination, nil
 }
 
-func (s *TicketService) GetTicketByID(id uint) (*model.Ticket, error) {
-	return s.ticketRepo.GetByID(id)
+// GetTicketByID returns the ticket to staff or to the member who raised it.
+func (s *TicketService) GetTicketByID(id uint, viewerID string, staff bool) (*model.Ticket, error) {
+	ticket, err := s.ticketRepo.GetByID(id)
+	if err != nil {
+		return nil, ErrTicketNotFound
+	}
+	if !canAccess(ticket, viewerID, staff) {
+		return nil, ErrTicketForbidden
+	}
+	return ticket, nil
 }
 
 func (s *TicketService) GetUserTickets(userID string, page, limit int) ([]model.Ticket, model.Pagination, error) {
@@ -45,12 +64,26 @@ func (s *TicketService) GetUserTickets(userID string, page, limit int) ([]model.
 }
 
 func (s *TicketService) CreateTicket(userID string, req *model.CreateTicketRequest) (*model.Ticket, error) {
+	var category *model.Category
+	if req.CategoryID != nil {
+		found, err := s.categoryRepo.GetByID(*req.CategoryID)
+		if err != nil {
+			return nil, errors.New("category not found")
+		}
+		category = found
+	}
+
+	now := time.Now()
+	firstResponseDue, resolutionDue := helper.TicketDueDates(now, category)
 	ticket := &model.Ticket{
-		UserID:      userID,
-		CategoryID:  req.CategoryID,
-		Title:       req.Title,
-		Description: req.Description,
-		Status:      model.TicketStatusUnread,
+		UserID:             userID,
+		CategoryID:         req.CategoryID,
+		Title:              req.Title,
+		Description:        req.Description,
+		Status:             model.TicketStatusUnread,
+		CreatedAt:          now,
+		FirstResponseDueAt: &firstResponseDue,
+		ResolutionDueAt:    &resolutionDue,
 	}
 
 	err := s.ticketRepo.Create(ticket)
@@ -58,47 +91,420 @@ func (s *TicketService) CreateTicket(userID string, req *model.CreateTicketReque
 		return nil, err
 	}
 
+	created, err := s.ticketRepo.GetByID(ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ticket is already saved, so a failed assignment must not fail the
+	// request. Tickets left unassigned wait for an admin.
+	if _, err := s.autoAssign(created); err != nil && !errors.Is(err, ErrNoAssignmentRule) {
+		config.Logger.Printf("auto-assign ticket %d: %v", created.ID, err)
+	}
+
 	return s.ticketRepo.GetByID(ticket.ID)
 }
 
-func (s *TicketService) UpdateTicket(id uint, req *model.UpdateTicketRequest) (*model.Ticket, error) {
-	_, err := s.ticketRepo.GetByID(id)
+// UpdateTicket lets staff change the resolution and move the ticket along
+// TicketStatusTransitions. Every status change is kept in the history.
+func (s *TicketService) UpdateTicket(id uint, actorID string, req *model.UpdateTicketRequest) (*model.Ticket, error) {
+	ticket, err := s.ticketRepo.GetByID(id)
 	if err != nil {
-		return nil, errors.New("ticket not found")
+		return nil, ErrTicketNotFound
 	}
 
-	updateData := &model.Ticket{}
-	
-	if req.Status != nil {
-		updateData.Status = *req.Status
-		
-		if *req.Status == model.TicketStatusResolved || *req.Status == model.TicketStatusClosed {
-			now := time.Now()
-			updateData.ResolvedAt = &now
+	if req.Status == nil || *req.Status == ticket.Status {
+		if req.Resolution != nil {
+			if err := s.ticketRepo.Update(id, &model.Ticket{Resolution: req.Resolution}); err != nil {
+				return nil, err
+			}
 		}
+		return s.ticketRepo.GetByID(id)
+	}
+
+	if !model.IsValidTicketStatus(*req.Status) {
+		return nil, fmt.Errorf("invalid status %q, must be one of unread, read, in_progress, resolved, closed", *req.Status)
+	}
+	if !model.CanTransitionTicketStatus(ticket.Status, *req.Status) {
+		return nil, fmt.Errorf("cannot move ticket from %s to %s", ticket.Status, *req.Status)
+	}
+
+	extra := map[string]interface{}{}
+	if ticket.Status.IsFinished() && !req.Status.IsFinished() {
+		extra = reopenColumns(ticket)
 	}
-	
 	if req.Resolution != nil {
-		updateData.Resolution = req.Resolution
+		extra["resolution"] = *req.Resolution
+	}
+
+	change := &model.TicketStatusHistory{
+		TicketID:   id,
+		FromStatus: ticket.Status,
+		ToStatus:   *req.Status,
+		ChangedBy:  &actorID,
+		Note:       req.Note,
+	}
+	if err := s.ticketRepo.ChangeStatus(change, true, extra); err != nil {
+		return nil, err
+	}
+
+	return s.ticketRepo.GetByID(id)
+}
+
+// ReopenTicket moves a resolved or closed ticket back to in progress.
+// Reporters may only do so within TICKET_REOPEN_DAYS of the resolution;
+// staff may reopen at any time.
+func (s *TicketService) ReopenTicket(id uint, actorID string, staff bool, req *model.ReopenTicketRequest) (*model.Ticket, error) {
+	ticket, err := s.ticketRepo.GetByID(id)
+	if err != nil {
+		return nil, ErrTicketNotFound
+	}
+	if !canAccess(ticket, actorID, staff) {
+		return nil, ErrTicketForbidden
+	}
+	if !ticket.Status.IsFinished() {
+		return nil, fmt.Errorf("only resolved or closed tickets can be reopened, ticket is %s", ticket.Status)
+	}
+	if !staff && ticket.ResolvedAt != nil {
+		deadline := helper.TicketReopenDeadline(*ticket.ResolvedAt)
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("ticket can no longer be reopened, the window ended on %s", deadline.Format("2006-01-02"))
+		}
+	}
+
+	extra := reopenColumns(ticket)
+	change := &model.TicketStatusHistory{
+		TicketID:   id,
+		FromStatus: ticket.Status,
+		ToStatus:   model.TicketStatusInProgress,
+		ChangedBy:  &actorID,
+		Note:       req.Note,
+	}
+	if err := s.ticketRepo.ChangeStatus(change, staff, extra); err != nil {
+		return nil, err
+	}
+
+	return s.ticketRepo.GetByID(id)
+}
+
+// reopenColumns gives a reopened ticket a fresh resolution target, measured
+// from now, so it is neither flagged at once nor exempt from a new breach.
+func reopenColumns(ticket *model.Ticket) map[string]interface{} {
+	_, resolutionDue := helper.TicketDueDates(time.Now(), ticket.Category)
+	return map[string]interface{}{
+		"resolution_due_at":      resolutionDue,
+		"resolution_breached_at": nil,
+	}
+}
+
+func (s *TicketService) GetStatusHistory(id uint, viewerID string, staff bool) ([]model.TicketStatusHistory, error) {
+	ticket, err := s.ticketRepo.GetByID(id)
+	if err != nil {
+		return nil, ErrTicketNotFound
+	}
+	if !canAccess(ticket, viewerID, staff) {
+		return nil, ErrTicketForbidden
+	}
+	return s.ticketRepo.GetStatusHistory(id)
+}
+
+var (
+	ErrTicketNotFound  = errors.New("ticket not found")
+	ErrTicketForbidden = errors.New("you do not have access to this ticket")
+)
+
+// canAccess lets staff into every ticket and members into their own.
+func canAccess(ticket *model.Ticket, viewerID string, staff bool) bool {
+	return staff || ticket.UserID == viewerID
+}
+
+// GetMessages returns the ticket thread as seen by the viewer and marks it
+// read for the viewer's side. Each message says whether the other side
+// has read it yet.
+func (s *TicketService) GetMessages(ticketID uint, viewerID string, staff bool) ([]model.TicketMessage, error) {
+	ticket, err := s.ticketRepo.GetByID(ticketID)
+	if err != nil {
+		return nil, ErrTicketNotFound
+	}
+	if !canAccess(ticket, viewerID, staff) {
+		return nil, ErrTicketForbidden
+	}
+
+	messages, err := s.ticketRepo.GetMessages(ticketID, staff)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range messages {
+		readAt := ticket.StaffReadAt
+		if messages[i].FromStaff {
+			readAt = ticket.ReporterReadAt
+		}
+		messages[i].ReadByOtherSide = readAt != nil && !readAt.Before(messages[i].CreatedAt)
+	}
+
+	if err := s.ticketRepo.MarkThreadRead(ticketID, staff, time.Now()); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// PostMessage adds a reply or, for staff, an internal note. A staff reply
+// to an unread or read ticket moves it to in progress and notifies the
+// reporter.
+func (s *TicketService) PostMessage(ticketID uint, authorID string, staff bool, req *model.CreateTicketMessageRequest) (*model.TicketMessage, error) {
+	ticket, err := s.ticketRepo.GetByID(ticketID)
+	if err != nil {
+		return nil, ErrTicketNotFound
+	}
+	if !canAccess(ticket, authorID, staff) {
+		return nil, ErrTicketForbidden
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		return nil, errors.New("body is required")
+	}
+	if req.IsInternal && !staff {
+		return nil, errors.New("only staff can post internal notes")
+	}
+	if ticket.Status == model.TicketStatusClosed && !staff {
+		return nil, errors.New("ticket is closed")
+	}
+
+	message := &model.TicketMessage{
+		TicketID:   ticketID,
+		UserID:     authorID,
+		Body:       req.Body,
+		IsInternal: req.IsInternal,
+		FromStaff:  staff,
+	}
+
+	var change *model.TicketStatusHistory
+	isReply := staff && !req.IsInternal
+	if isReply && (ticket.Status == model.TicketStatusUnread || ticket.Status == model.TicketStatusRead) {
+		note := "Staff replied"
+		change = &model.TicketStatusHistory{
+			TicketID:   ticketID,
+			FromStatus: ticket.Status,
+			ToStatus:   model.TicketStatusInProgress,
+			ChangedBy:  &authorID,
+			Note:       &note,
+		}
+	}
+
+	if err := s.ticketRepo.CreateMessage(message, change); err != nil {
+		return nil, err
+	}
+
+	// Writing a message means the author has read the thread up to it
+	if err := s.ticketRepo.MarkThreadRead(ticketID, staff, message.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if isReply {
+		s.notificationSvc.Notify(ticket.UserID, "ticket_reply", "New reply on your ticket",
+			"Staff replied to \""+ticket.Title+"\".")
 	}
 
-	err = s.ticketRepo.Update(id, updateData)
+	return message, nil
+}
+
+var ErrNoAssignmentRule = errors.New("no active assignment rule covers this ticket")
+
+// AssignTicket hands the ticket to a staff member, or unassigns it when
+// no assignee is given.
+func (s *TicketService) AssignTicket(id uint, actorID string, req *model.AssignTicketRequest) (*model.Ticket, error) {
+	ticket, err := s.ticketRepo.GetByID(id)
 	if err != nil {
+		return nil, ErrTicketNotFound
+	}
+
+	if req.AssigneeID == nil || *req.AssigneeID == "" {
+		if err := s.ticketRepo.Assign(id, nil, nil); err != nil {
+			return nil, err
+		}
+		return s.ticketRepo.GetByID(id)
+	}
+
+	if err := s.checkStaff(*req.AssigneeID); err != nil {
 		return nil, err
 	}
+	if err := s.ticketRepo.Assign(id, req.AssigneeID, &actorID); err != nil {
+		return nil, err
+	}
+
+	s.notifyAssignee(*req.AssigneeID, ticket)
+	return s.ticketRepo.GetByID(id)
+}
 
+// AutoAssignTicket runs the assignment rules against an existing ticket.
+func (s *TicketService) AutoAssignTicket(id uint) (*model.Ticket, error) {
+	ticket, err := s.ticketRepo.GetByID(id)
+	if err != nil {
+		return nil, ErrTicketNotFound
+	}
+	if _, err := s.autoAssign(ticket); err != nil {
+		return nil, err
+	}
 	return s.ticketRepo.GetByID(id)
 }
 
+// autoAssign picks the rule for the reporter's village or the ticket
+// category and gives the ticket to the next staff member in it.
+func (s *TicketService) autoAssign(ticket *model.Ticket) (string, error) {
+	var villageID *uint
+	if ticket.User != nil {
+		villageID = ticket.User.VillageID
+	}
+
+	rule, err := s.ticketRepo.FindAssignmentRule(ticket.CategoryID, villageID)
+	if err != nil {
+		return "", err
+	}
+	if rule == nil {
+		return "", ErrNoAssignmentRule
+	}
+
+	assigneeID, err := s.ticketRepo.AssignByRule(rule.ID, ticket.ID)
+	if err != nil {
+		return "", err
+	}
+
+	s.notifyAssignee(assigneeID, ticket)
+	return assigneeID, nil
+}
+
+func (s *TicketService) notifyAssignee(assigneeID string, ticket *model.Ticket) {
+	s.notificationSvc.Notify(assigneeID, "ticket_assigned", "Ticket assigned to you",
+		"You are now handling \""+ticket.Title+"\".")
+}
+
+// checkStaff makes sure the user exists and may handle tickets.
+func (s *TicketService) checkStaff(userID string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("user %s not found", userID)
+	}
+	if !helper.IsStaffRole(user.RoleID) {
+		return fmt.Errorf("user %s is not staff", userID)
+	}
+	return nil
+}
+
+func (s *TicketService) GetAssignedTickets(assigneeID string, page, limit int, status *model.TicketStatus) ([]model.Ticket, model.Pagination, error) {
+	offset := (page - 1) * limit
+	tickets, total, err := s.ticketRepo.GetByAssignee(assigneeID, limit, offset, status)
+	if err != nil {
+		return nil, model.Pagination{}, err
+	}
+
+	pagination := helper.CreatePagination(int64(page), int64(limit), total)
+	return tickets, pagination, nil
+}
+
+func (s *TicketService) GetWorkload() ([]model.AssigneeWorkload, error) {
+	now := time.Now()
+	return s.ticketRepo.GetWorkload(now, helper.TicketOverdueBefore(now))
+}
+
+func (s *TicketService) GetAssignmentRules() ([]model.TicketAssignmentRule, error) {
+	return s.ticketRepo.GetAssignmentRules()
+}
+
+func (s *TicketService) CreateAssignmentRule(req *model.TicketAssignmentRuleRequest) (*model.TicketAssignmentRule, error) {
+	rule := &model.TicketAssignmentRule{IsActive: true}
+	return s.saveAssignmentRule(rule, req)
+}
+
+func (s *TicketService) UpdateAssignmentRule(id uint, req *model.TicketAssignmentRuleRequest) (*model.TicketAssignmentRule, error) {
+	rule, err := s.ticketRepo.GetAssignmentRuleByID(id)
+	if err != nil {
+		return nil, errors.New("assignment rule not found")
+	}
+	return s.saveAssignmentRule(rule, req)
+}
+
+// saveAssignmentRule applies the request to the rule. A rule covers either
+// a category or a village, and each of those has at most one rule.
+func (s *TicketService) saveAssignmentRule(rule *model.TicketAssignmentRule, req *model.TicketAssignmentRuleRequest) (*model.TicketAssignmentRule, error) {
+	if (req.CategoryID == nil) == (req.VillageID == nil) {
+		return nil, errors.New("set exactly one of category_id or village_id")
+	}
+	if req.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(*req.CategoryID); err != nil {
+			return nil, errors.New("category not found")
+		}
+	}
+	if req.VillageID != nil {
+		if _, err := s.villageRepo.GetByID(*req.VillageID); err != nil {
+			return nil, errors.New("village not found")
+		}
+	}
+	if len(req.StaffIDs) == 0 {
+		return nil, errors.New("staff_ids is required")
+	}
+
+	existing, err := s.ticketRepo.CountAssignmentRules(req.CategoryID, req.VillageID, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, errors.New("an assignment rule already covers this category or village")
+	}
+
+	staff := make([]model.User, 0, len(req.StaffIDs))
+	for _, id := range req.StaffIDs {
+		if err := s.checkStaff(id); err != nil {
+			return nil, err
+		}
+		staff = append(staff, model.User{ID: id})
+	}
+
+	rule.CategoryID = req.CategoryID
+	rule.VillageID = req.VillageID
+	rule.IsActive = helper.GetBoolValue(req.IsActive, rule.IsActive)
+	rule.Staff = staff
+	rule.Category = nil
+	rule.Village = nil
+
+	if err := s.ticketRepo.SaveAssignmentRule(rule); err != nil {
+		return nil, err
+	}
+	return s.ticketRepo.GetAssignmentRuleByID(rule.ID)
+}
+
+func (s *TicketService) DeleteAssignmentRule(id uint) error {
+	rule, err := s.ticketRepo.GetAssignmentRuleByID(id)
+	if err != nil {
+		return errors.New("assignment rule not found")
+	}
+	return s.ticketRepo.DeleteAssignmentRule(rule)
+}
+
 func (s *TicketService) DeleteTicket(id uint) error {
 	_, err := s.ticketRepo.GetByID(id)
 	if err != nil {
-		return errors.New("ticket not found")
+		return ErrTicketNotFound
 	}
 
 	return s.ticketRepo.Delete(id)
 }
 
-func (s *TicketService) GetTicketStats() (map[model.TicketStatus]int64, error) {
-	return s.ticketRepo.GetCountByStatus()
+// GetTicketStats returns the count per status alongside SLA compliance
+// under the "sla" key.
+func (s *TicketService) GetTicketStats(region *model.RegionFilter) (map[string]interface{}, error) {
+	counts, err := s.ticketRepo.GetCountByStatus(region)
+	if err != nil {
+		return nil, err
+	}
+
+	sla, err := s.ticketRepo.GetSLAStats(region, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	stats := map[string]interface{}{"sla": sla}
+	for status, count := range counts {
+		stats[string(status)] = count
+	}
+	return stats, nil
 }
\ No newline at end of file
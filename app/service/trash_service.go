@@ -0,0 +1,114 @@
+package service
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/helper"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TrashService struct {
+	userRepo     *repository.UserRepository
+	articleRepo  *repository.ArticleRepository
+	ticketRepo   *repository.TicketRepository
+	documentRepo *repository.DocumentRepository
+	villageRepo  *repository.VillageRepository
+	categoryRepo *repository.CategoryRepository
+}
+
+func NewTrashService() *TrashService {
+	return &TrashService{
+		userRepo:     repository.NewUserRepository(),
+		articleRepo:  repository.NewArticleRepository(),
+		ticketRepo:   repository.NewTicketRepository(),
+		documentRepo: repository.NewDocumentRepository(),
+		villageRepo:  repository.NewVillageRepository(),
+		categoryRepo: repository.NewCategoryRepository(),
+	}
+}
+
+func (s *TrashService) GetAll(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var items interface{}
+	var total int64
+	var err error
+
+	switch c.Params("type") {
+	case "users":
+		items, total, err = s.userRepo.GetDeleted(limit, offset)
+	case "articles":
+		items, total, err = s.articleRepo.GetDeleted(limit, offset)
+	case "tickets":
+		items, total, err = s.ticketRepo.GetDeleted(limit, offset)
+	case "documents":
+		items, total, err = s.documentRepo.GetDeleted(limit, offset)
+	case "villages":
+		items, total, err = s.villageRepo.GetDeleted(limit, offset)
+	case "categories":
+		items, total, err = s.categoryRepo.GetDeleted(limit, offset)
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Unknown trash type",
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	pagination := helper.CreatePagination(int64(page), int64(limit), total)
+
+	return c.JSON(model.PaginatedResponse{
+		Success:    true,
+		Message:    "Deleted items retrieved successfully",
+		Data:       helper.MaskSensitive(c, items),
+		Pagination: pagination,
+	})
+}
+
+func (s *TrashService) Restore(c *fiber.Ctx) error {
+	id := c.Params("id")
+	numericID, _ := strconv.ParseUint(id, 10, 32)
+
+	var err error
+	switch c.Params("type") {
+	case "users":
+		err = s.userRepo.Restore(id)
+	case "articles":
+		err = s.articleRepo.Restore(uint(numericID))
+	case "tickets":
+		err = s.ticketRepo.Restore(uint(numericID))
+	case "documents":
+		err = s.documentRepo.Restore(uint(numericID))
+	case "villages":
+		err = s.villageRepo.Restore(uint(numericID))
+	case "categories":
+		err = s.categoryRepo.Restore(uint(numericID))
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Unknown trash type",
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Deleted item not found",
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Item restored successfully",
+	})
+}
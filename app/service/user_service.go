@@ -1,22 +1,27 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/app/repository"
 	"arek-muhammadiyah-be/helper"
 	"arek-muhammadiyah-be/helper/utils"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo       *repository.UserRepository
+	invitationRepo *repository.InvitationRepository
 }
 
 func NewUserService() *UserService {
 	return &UserService{
-		userRepo: repository.NewUserRepository(),
+		userRepo:       repository.NewUserRepository(),
+		invitationRepo: repository.NewInvitationRepository(),
 	}
 }
 
@@ -25,7 +30,15 @@ func (s *UserService) GetAll(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	offset := (page - 1) * limit
 
-	users, total, err := s.userRepo.GetAll(limit, offset)
+	filter, err := parseUserFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	users, total, err := s.userRepo.GetAll(limit, offset, filter)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
 			Success: false,
@@ -38,7 +51,7 @@ func (s *UserService) GetAll(c *fiber.Ctx) error {
 	return c.JSON(model.PaginatedResponse{
 		Success:    true,
 		Message:    "Users retrieved successfully",
-		Data:       users,
+		Data:       helper.MaskSensitive(c, users),
 		Pagination: pagination,
 	})
 }
@@ -56,7 +69,7 @@ func (s *UserService) GetByID(c *fiber.Ctx) error {
 	return c.JSON(model.Response{
 		Success: true,
 		Message: "User retrieved successfully",
-		Data:    user,
+		Data:    helper.MaskSensitive(c, user),
 	})
 }
 
@@ -69,12 +82,18 @@ func (s *UserService) CreateUser(c *fiber.Ctx) error {
 		})
 	}
 
-	hashedPassword, err := utils.HashPassword(req.Password)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
-			Success: false,
-			Message: "Failed to hash password",
-		})
+	// Without a password the member activates the account through an
+	// invitation instead
+	var hashedPassword string
+	if req.Password != "" {
+		var err error
+		hashedPassword, err = utils.HashPassword(req.Password)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: "Failed to hash password",
+			})
+		}
 	}
 
 	user := &model.User{
@@ -86,21 +105,57 @@ func (s *UserService) CreateUser(c *fiber.Ctx) error {
 		VillageID:  req.VillageID,
 		NIK:        req.NIK,
 		Address:    req.Address,
-		CardStatus: helper.GetStringValue(req.CardStatus, "pending"),
+		CardStatus: helper.GetStringValue(req.CardStatus, model.CardStatusPending),
 		IsMobile:   helper.GetBoolValue(req.IsMobile, false),
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	if err := helper.ApplyUserProfile(user, &req.UserProfileRequest); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
 			Success: false,
 			Message: err.Error(),
 		})
 	}
 
+	referrer, err := resolveReferrer(s.userRepo, &req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	user.ReferredBy = referrer
+	user.ReferralCode = newReferralCode(s.userRepo)
+
+	if err := createMember(s.userRepo, user); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	if user.Password == "" {
+		invitation, err := sendInvitation(s.invitationRepo, user.ID, c.Locals("user_id").(string))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: "User created but the invitation failed: " + err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(model.Response{
+			Success: true,
+			Message: "User created and invited successfully",
+			Data: fiber.Map{
+				"user":       helper.MaskSensitive(c, user),
+				"invitation": invitation,
+			},
+		})
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(model.Response{
 		Success: true,
 		Message: "User created successfully",
-		Data:    user,
+		Data:    helper.MaskSensitive(c, user),
 	})
 }
 
@@ -130,22 +185,85 @@ func (s *UserService) Update(c *fiber.Ctx) error {
 		NIK:        helper.GetStringPointer(req.NIK, existing.NIK),
 		Address:    helper.GetStringPointer(req.Address, existing.Address),
 		CardStatus: helper.GetStringValue(req.CardStatus, existing.CardStatus),
+
+		BirthPlace:    existing.BirthPlace,
+		BirthDate:     existing.BirthDate,
+		Gender:        existing.Gender,
+		Occupation:    existing.Occupation,
+		Education:     existing.Education,
+		MaritalStatus: existing.MaritalStatus,
+		Ranting:       existing.Ranting,
+		Cabang:        existing.Cabang,
+		Daerah:        existing.Daerah,
 	}
 
+	if err := helper.ApplyUserProfile(updateData, &req.UserProfileRequest); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	if req.CardStatus != nil && !model.IsValidCardStatus(*req.CardStatus) {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid card status",
+		})
+	}
+
+	cardStatus := updateData.CardStatus
+	statusChanged := cardStatus != existing.CardStatus
+	if statusChanged && !model.CanTransitionCardStatus(existing.CardStatus, cardStatus) {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: fmt.Sprintf("Cannot move card from %s to %s", existing.CardStatus, cardStatus),
+		})
+	}
+
+	// The card status is written below together with its history row, so
+	// leave it out of the profile update
+	updateData.CardStatus = ""
 	if err := s.userRepo.Update(id, updateData); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
 			Success: false,
 			Message: err.Error(),
 		})
 	}
+	updateData.CardStatus = existing.CardStatus
+
+	if statusChanged {
+		var extra map[string]interface{}
+		if cardStatus == model.CardStatusPrinted {
+			extra = helper.CardIssueColumns(time.Now())
+		}
+
+		adminID := c.Locals("user_id").(string)
+		change := model.CardStatusHistory{
+			UserID:     id,
+			FromStatus: existing.CardStatus,
+			ToStatus:   cardStatus,
+			ChangedBy:  &adminID,
+		}
+		if err := s.userRepo.UpdateCardStatuses([]model.CardStatusHistory{change}, extra); err != nil {
+			return c.Status(fiber.StatusConflict).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		updateData.CardStatus = cardStatus
+	}
 
 	return c.JSON(model.Response{
 		Success: true,
 		Message: "User updated successfully",
-		Data:    updateData,
+		Data:    helper.MaskSensitive(c, updateData),
 	})
 }
 
+func (s *UserService) UploadPhoto(c *fiber.Ctx) error {
+	return uploadUserPhoto(c, s.userRepo, c.Params("id"))
+}
+
 func (s *UserService) Delete(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if _, err := s.userRepo.GetByID(id); err != nil {
@@ -168,12 +286,113 @@ func (s *UserService) Delete(c *fiber.Ctx) error {
 	})
 }
 
+func (s *UserService) Suspend(c *fiber.Ctx) error {
+	id := c.Params("id")
+	adminID := c.Locals("user_id").(string)
+	var req model.SuspendUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Suspension reason is required",
+		})
+	}
+
+	if id == adminID {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "You cannot suspend your own account",
+		})
+	}
+
+	if _, err := s.userRepo.GetByID(id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "User not found",
+		})
+	}
+
+	var until *time.Time
+	if req.Until != nil && *req.Until != "" {
+		date, err := helper.ParseDate(*req.Until)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		until = date
+	}
+
+	err := s.userRepo.UpdateColumns(id, map[string]interface{}{
+		"is_active":        false,
+		"suspended_reason": req.Reason,
+		"suspended_until":  until,
+		"suspended_at":     time.Now(),
+		"suspended_by":     adminID,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	user, _ := s.userRepo.GetByID(id)
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "User suspended successfully",
+		Data:    helper.MaskSensitive(c, user),
+	})
+}
+
+func (s *UserService) Reactivate(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := s.userRepo.GetByID(id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "User not found",
+		})
+	}
+
+	err := s.userRepo.UpdateColumns(id, map[string]interface{}{
+		"is_active":        true,
+		"suspended_reason": nil,
+		"suspended_until":  nil,
+		"suspended_at":     nil,
+		"suspended_by":     nil,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	user, _ := s.userRepo.GetByID(id)
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "User reactivated successfully",
+		Data:    helper.MaskSensitive(c, user),
+	})
+}
+
 func (s *UserService) BulkCreate(c *fiber.Ctx) error {
-	file, err := c.FormFile("csv")
+	// "csv" is kept as the field name for older clients
+	file, err := c.FormFile("file")
+	if err != nil {
+		file, err = c.FormFile("csv")
+	}
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
 			Success: false,
-			Message: "CSV file required",
+			Message: "CSV or XLSX file required",
 		})
 	}
 
@@ -181,16 +400,16 @@ func (s *UserService) BulkCreate(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
 			Success: false,
-			Message: "Failed to open CSV file",
+			Message: "Failed to open file",
 		})
 	}
 	defer src.Close()
 
-	users, err := helper.ParseUsersFromCSV(src)
+	users, err := helper.ParseUsersFromFile(file.Filename, src, c.FormValue("sheet"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
 			Success: false,
-			Message: "Failed to parse CSV: " + err.Error(),
+			Message: "Failed to parse file: " + err.Error(),
 		})
 	}
 
@@ -198,9 +417,14 @@ func (s *UserService) BulkCreate(c *fiber.Ctx) error {
 	var failedUsers []string
 
 	for _, userReq := range users {
+		if userReq.Name == "" {
+			failedUsers = append(failedUsers, importRowLabel(userReq))
+			continue
+		}
+
 		hashedPassword, err := utils.HashPassword(userReq.Password)
 		if err != nil {
-			failedUsers = append(failedUsers, userReq.ID)
+			failedUsers = append(failedUsers, importRowLabel(userReq))
 			continue
 		}
 
@@ -213,12 +437,25 @@ func (s *UserService) BulkCreate(c *fiber.Ctx) error {
 			VillageID:  userReq.VillageID,
 			NIK:        userReq.NIK,
 			Address:    userReq.Address,
-			CardStatus: helper.GetStringValue(userReq.CardStatus, "pending"),
+			CardStatus: helper.GetStringValue(userReq.CardStatus, model.CardStatusPending),
 			IsMobile:   helper.GetBoolValue(userReq.IsMobile, false),
 		}
 
-		if err := s.userRepo.Create(u); err != nil {
-			failedUsers = append(failedUsers, userReq.ID)
+		if err := helper.ApplyUserProfile(u, &userReq.UserProfileRequest); err != nil {
+			failedUsers = append(failedUsers, importRowLabel(userReq))
+			continue
+		}
+
+		referrer, err := resolveReferrer(s.userRepo, &userReq)
+		if err != nil {
+			failedUsers = append(failedUsers, importRowLabel(userReq))
+			continue
+		}
+		u.ReferredBy = referrer
+		u.ReferralCode = newReferralCode(s.userRepo)
+
+		if err := createMember(s.userRepo, u); err != nil {
+			failedUsers = append(failedUsers, importRowLabel(userReq))
 			continue
 		}
 
@@ -230,7 +467,7 @@ func (s *UserService) BulkCreate(c *fiber.Ctx) error {
 			Success: false,
 			Message: "Some users failed to create",
 			Data: fiber.Map{
-				"created": createdUsers,
+				"created": helper.MaskSensitive(c, createdUsers),
 				"failed":  failedUsers,
 			},
 		})
@@ -239,7 +476,140 @@ func (s *UserService) BulkCreate(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(model.Response{
 		Success: true,
 		Message: "Users created successfully",
-		Data:    createdUsers,
+		Data:    helper.MaskSensitive(c, createdUsers),
+	})
+}
+
+func (s *UserService) BulkUpdateCardStatus(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(string)
+	var req model.BulkCardStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if !model.IsValidCardStatus(req.ToStatus) {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid target card status",
+		})
+	}
+
+	if len(req.IDs) == 0 && req.VillageID == nil && req.CardStatus == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Provide ids or a village_id/card_status filter",
+		})
+	}
+
+	filter := &model.UserFilter{
+		VillageID:  req.VillageID,
+		CardStatus: helper.GetStringValue(req.CardStatus, ""),
+	}
+	users, err := s.userRepo.GetForBulk(req.IDs, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	if len(users) > repository.MaxBulkUsers {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: fmt.Sprintf("At most %d members can be updated at once", repository.MaxBulkUsers),
+		})
+	}
+
+	found := make(map[string]bool, len(users))
+	var results []model.BulkCardStatusResult
+	var changes []model.CardStatusHistory
+	invalid := 0
+
+	for _, user := range users {
+		found[user.ID] = true
+		result := model.BulkCardStatusResult{
+			UserID:     user.ID,
+			FromStatus: user.CardStatus,
+			ToStatus:   req.ToStatus,
+		}
+
+		if !model.CanTransitionCardStatus(user.CardStatus, req.ToStatus) {
+			result.Message = fmt.Sprintf("cannot move card from %s to %s", user.CardStatus, req.ToStatus)
+			invalid++
+		} else {
+			result.Success = true
+			changes = append(changes, model.CardStatusHistory{
+				UserID:     user.ID,
+				FromStatus: user.CardStatus,
+				ToStatus:   req.ToStatus,
+				ChangedBy:  &adminID,
+				Note:       req.Note,
+			})
+		}
+		results = append(results, result)
+	}
+
+	for _, id := range req.IDs {
+		if !found[id] {
+			results = append(results, model.BulkCardStatusResult{
+				UserID:   id,
+				ToStatus: req.ToStatus,
+				Message:  "user not found",
+			})
+			invalid++
+		}
+	}
+
+	if invalid > 0 && !req.SkipInvalid {
+		// Nothing is applied; mark the valid ones as not updated either
+		for i := range results {
+			if results[i].Success {
+				results[i].Success = false
+				results[i].Message = "not applied, batch contains invalid transitions"
+			}
+		}
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(model.Response{
+			Success: false,
+			Message: "Some card status transitions are invalid",
+			Data:    results,
+		})
+	}
+
+	var extra map[string]interface{}
+	if req.ToStatus == model.CardStatusPrinted {
+		extra = helper.CardIssueColumns(time.Now())
+	}
+
+	if err := s.userRepo.UpdateCardStatuses(changes, extra); err != nil {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: fmt.Sprintf("%d card statuses updated", len(changes)),
+		Data:    results,
+	})
+}
+
+func (s *UserService) GetCardStatusHistory(c *fiber.Ctx) error {
+	history, err := s.userRepo.GetCardStatusHistory(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Card status history retrieved successfully",
+		Data:    history,
 	})
 }
 
@@ -262,7 +632,7 @@ func (s *UserService) GetByVillage(c *fiber.Ctx) error {
 	return c.JSON(model.PaginatedResponse{
 		Success:    true,
 		Message:    "Users retrieved successfully",
-		Data:       users,
+		Data:       helper.MaskSensitive(c, users),
 		Pagination: pagination,
 	})
 }
@@ -286,7 +656,76 @@ func (s *UserService) GetByCardStatus(c *fiber.Ctx) error {
 	return c.JSON(model.PaginatedResponse{
 		Success:    true,
 		Message:    "Users retrieved successfully",
-		Data:       users,
+		Data:       helper.MaskSensitive(c, users),
 		Pagination: pagination,
 	})
-}
\ No newline at end of file
+}
+
+func parseUserFilter(c *fiber.Ctx) (*model.UserFilter, error) {
+	filter := &model.UserFilter{
+		CardStatus: c.Query("card_status"),
+		Occupation: c.Query("occupation"),
+		Education:  c.Query("education"),
+		Ranting:    c.Query("ranting"),
+		Cabang:     c.Query("cabang"),
+		Daerah:     c.Query("daerah"),
+
+		RegionFilter: helper.ParseRegionFilter(c),
+	}
+
+	if villageIDStr := c.Query("village_id"); villageIDStr != "" {
+		villageID, err := strconv.ParseUint(villageIDStr, 10, 32)
+		if err != nil {
+			return nil, errors.New("invalid village_id")
+		}
+		id := uint(villageID)
+		filter.VillageID = &id
+	}
+
+	if active := c.Query("is_active"); active != "" {
+		isActive := active == "true"
+		filter.IsActive = &isActive
+	}
+
+	if gender := c.Query("gender"); gender != "" {
+		normalized, err := helper.NormalizeGender(gender)
+		if err != nil {
+			return nil, err
+		}
+		filter.Gender = normalized
+	}
+
+	if status := c.Query("marital_status"); status != "" {
+		normalized, err := helper.NormalizeMaritalStatus(status)
+		if err != nil {
+			return nil, err
+		}
+		filter.MaritalStatus = normalized
+	}
+
+	return filter, nil
+}
+
+// createMember keeps an ID given by an admin, for example an existing card
+// number, and otherwise generates the next member number.
+func createMember(userRepo *repository.UserRepository, user *model.User) error {
+	if user.ID != "" {
+		return userRepo.Create(user)
+	}
+	year := time.Now().Year()
+	return userRepo.CreateWithMemberNumber(user, year, func(villageCode string, seq int) string {
+		return helper.FormatMemberNumber(villageCode, year, seq)
+	})
+}
+
+// importRowLabel identifies a failed import row by its ID, or by name when
+// the ID was left for generation, or else by its row number.
+func importRowLabel(req model.CreateUserRequest) string {
+	if req.ID != "" {
+		return req.ID
+	}
+	if req.Name != "" {
+		return req.Name
+	}
+	return fmt.Sprintf("row %d", req.Row)
+}
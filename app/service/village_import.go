@@ -0,0 +1,282 @@
+package service
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/helper"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// villageImportPlan is what an import would do, worked out before anything
+// is written so the same plan backs both the dry run and the real import.
+type villageImportPlan struct {
+	results []model.VillageImportResult
+	creates []model.Village
+	updates map[uint]map[string]interface{}
+	invalid int
+}
+
+// ImportVillages upserts villages by code from a CSV, XLSX or GeoJSON
+// file. With dry_run=true it only returns the per-row diff. A file with
+// any invalid row is rejected as a whole.
+func (s *VillageService) ImportVillages(c *fiber.Ctx) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "CSV, XLSX or GeoJSON file required",
+		})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: "Failed to open file",
+		})
+	}
+	defer src.Close()
+
+	rows, err := helper.ParseVillagesFromFile(file.Filename, src, c.FormValue("sheet"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Failed to parse file: " + err.Error(),
+		})
+	}
+
+	plan, err := s.planImport(rows)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	summary := fiber.Map{
+		"create":    len(plan.creates),
+		"update":    len(plan.updates),
+		"unchanged": len(plan.results) - len(plan.creates) - len(plan.updates) - plan.invalid,
+		"invalid":   plan.invalid,
+	}
+
+	if c.FormValue("dry_run") == "true" {
+		return c.JSON(model.Response{
+			Success: true,
+			Message: "Village import preview",
+			Data: fiber.Map{
+				"summary": summary,
+				"rows":    plan.results,
+			},
+		})
+	}
+
+	if plan.invalid > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Import has invalid rows, nothing was saved",
+			Data: fiber.Map{
+				"summary": summary,
+				"rows":    plan.results,
+			},
+		})
+	}
+
+	if err := s.villageRepo.Import(plan.creates, plan.updates); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Villages imported successfully",
+		Data: fiber.Map{
+			"summary": summary,
+			"rows":    plan.results,
+		},
+	})
+}
+
+func (s *VillageService) planImport(rows []model.ImportVillageRequest) (*villageImportPlan, error) {
+	plan := &villageImportPlan{updates: make(map[uint]map[string]interface{})}
+
+	codes := make([]string, 0, len(rows))
+	for _, row := range rows {
+		codes = append(codes, row.Code)
+	}
+	existing, err := s.villageRepo.GetByCodes(codes)
+	if err != nil {
+		return nil, err
+	}
+	byCode := make(map[string]model.Village, len(existing))
+	for _, village := range existing {
+		byCode[village.Code] = village
+	}
+
+	seen := make(map[string]int)
+	districts := make(map[string]bool)
+
+	for _, row := range rows {
+		result := model.VillageImportResult{Row: row.Row, Code: row.Code}
+		invalid := func(message string) {
+			result.Action = model.ImportActionInvalid
+			result.Message = message
+			plan.invalid++
+		}
+
+		if err := s.validateImportRow(row, seen, districts); err != nil {
+			invalid(err.Error())
+			plan.results = append(plan.results, result)
+			continue
+		}
+		seen[row.Code] = row.Row
+
+		var lat, lng float64
+		if row.Boundary != nil {
+			lat, lng, err = helper.GeometryCentroid(row.Boundary)
+			if err != nil {
+				invalid(err.Error())
+				plan.results = append(plan.results, result)
+				continue
+			}
+		}
+
+		village, exists := byCode[row.Code]
+		switch {
+		case exists && village.DeletedAt.Valid:
+			invalid("a village with this code is in the trash, restore it first")
+		case !exists && row.Name == nil:
+			invalid("name is required for a new village")
+		case !exists:
+			created := model.Village{
+				Name:         *row.Name,
+				Code:         row.Code,
+				Description:  row.Description,
+				Color:        helper.GetStringValue(row.Color, "#3B82F6"),
+				IsActive:     helper.GetBoolValue(row.IsActive, true),
+				DistrictCode: row.DistrictCode,
+			}
+			if row.Boundary != nil {
+				boundary := string(row.Boundary)
+				created.Boundary = &boundary
+				created.CentroidLat = &lat
+				created.CentroidLng = &lng
+			}
+			plan.creates = append(plan.creates, created)
+			result.Action = model.ImportActionCreate
+		default:
+			changes, columns := villageChanges(village, row)
+			if row.Boundary != nil {
+				changes["boundary"] = model.FieldChange{From: village.Boundary != nil, To: true}
+				columns["boundary"] = string(row.Boundary)
+				columns["centroid_lat"] = lat
+				columns["centroid_lng"] = lng
+			}
+			if len(columns) == 0 {
+				result.Action = model.ImportActionUnchanged
+				break
+			}
+			plan.updates[village.ID] = columns
+			result.Action = model.ImportActionUpdate
+			result.Changes = changes
+		}
+
+		plan.results = append(plan.results, result)
+	}
+
+	return plan, nil
+}
+
+// validateImportRow checks the code is present and unique within the file
+// and that a given district exists. Known districts are cached across rows.
+func (s *VillageService) validateImportRow(row model.ImportVillageRequest, seen map[string]int, districts map[string]bool) error {
+	if row.Code == "" {
+		return errors.New("code is required")
+	}
+	if first, ok := seen[row.Code]; ok {
+		return fmt.Errorf("duplicate code, already used on row %d", first)
+	}
+	if row.DistrictCode != nil {
+		code := *row.DistrictCode
+		if _, checked := districts[code]; !checked {
+			_, err := s.regionRepo.GetDistrict(code)
+			districts[code] = err == nil
+		}
+		if !districts[code] {
+			return fmt.Errorf("district %s not found", code)
+		}
+	}
+	return nil
+}
+
+// villageChanges compares the fields present in the row with the stored
+// village and returns both the diff and the columns to update.
+func villageChanges(village model.Village, row model.ImportVillageRequest) (map[string]model.FieldChange, map[string]interface{}) {
+	changes := make(map[string]model.FieldChange)
+	columns := make(map[string]interface{})
+
+	compare := func(column string, from, to interface{}) {
+		if from != to {
+			changes[column] = model.FieldChange{From: from, To: to}
+			columns[column] = to
+		}
+	}
+
+	if row.Name != nil {
+		compare("name", village.Name, *row.Name)
+	}
+	if row.Description != nil {
+		compare("description", helper.GetStringValue(village.Description, ""), *row.Description)
+	}
+	if row.Color != nil {
+		compare("color", village.Color, *row.Color)
+	}
+	if row.IsActive != nil {
+		compare("is_active", village.IsActive, *row.IsActive)
+	}
+	if row.DistrictCode != nil {
+		compare("district_code", helper.GetStringValue(village.DistrictCode, ""), *row.DistrictCode)
+	}
+
+	return changes, columns
+}
+
+// BulkUpdateStatus activates or deactivates villages selected by ID or
+// code.
+func (s *VillageService) BulkUpdateStatus(c *fiber.Ctx) error {
+	var req model.BulkVillageStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if len(req.IDs) == 0 && len(req.Codes) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "ids or codes required",
+		})
+	}
+
+	updated, err := s.villageRepo.SetActive(req.IDs, req.Codes, req.IsActive)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Villages updated successfully",
+		Data: fiber.Map{
+			"updated":   updated,
+			"is_active": req.IsActive,
+		},
+	})
+}
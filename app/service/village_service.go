@@ -1,21 +1,26 @@
 package service
 
 import (
+	"encoding/json"
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/app/repository"
 	"arek-muhammadiyah-be/helper"
+	"io"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type VillageService struct {
 	villageRepo *repository.VillageRepository
+	regionRepo  *repository.RegionRepository
 }
 
 func NewVillageService() *VillageService {
 	return &VillageService{
 		villageRepo: repository.NewVillageRepository(),
+		regionRepo:  repository.NewRegionRepository(),
 	}
 }
 
@@ -25,7 +30,8 @@ func (s *VillageService) GetAll(c *fiber.Ctx) error {
 	activeOnly := c.Query("active", "false") == "true"
 	offset := (page - 1) * limit
 
-	villages, total, err := s.villageRepo.GetAll(limit, offset, activeOnly)
+	region := helper.ParseRegionFilter(c)
+	villages, total, err := s.villageRepo.GetAll(limit, offset, activeOnly, &region)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
 			Success: false,
@@ -59,6 +65,281 @@ func (s *VillageService) GetWithUserCount(c *fiber.Ctx) error {
 	})
 }
 
+// GetMapGeoJSON returns active villages as a GeoJSON FeatureCollection
+// that map libraries can load directly. Villages without a boundary have a
+// null geometry but still carry their counts.
+func (s *VillageService) GetMapGeoJSON(c *fiber.Ctx) error {
+	villages, err := s.villageRepo.GetWithUserCount()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	cardCounts, err := s.villageRepo.GetCardStatusCounts()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	collection := model.GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]model.GeoJSONFeature, 0, len(villages)),
+	}
+	for _, village := range villages {
+		geometry := json.RawMessage("null")
+		if village.Boundary != nil {
+			geometry = json.RawMessage(*village.Boundary)
+		}
+
+		cardStatus := cardCounts[village.ID]
+		if cardStatus == nil {
+			cardStatus = map[string]int64{}
+		}
+
+		collection.Features = append(collection.Features, model.GeoJSONFeature{
+			Type:     "Feature",
+			ID:       village.ID,
+			Geometry: geometry,
+			Properties: map[string]interface{}{
+				"id":               village.ID,
+				"name":             village.Name,
+				"code":             village.Code,
+				"color":            village.Color,
+				"district_code":    village.DistrictCode,
+				"centroid_lat":     village.CentroidLat,
+				"centroid_lng":     village.CentroidLng,
+				"total_users":      village.TotalUsers,
+				"total_households": village.TotalHouseholds,
+				"card_status":      cardStatus,
+			},
+		})
+	}
+
+	return c.JSON(collection, "application/geo+json")
+}
+
+// UploadBoundaries reads a GeoJSON file and stores each feature's geometry
+// on the village whose code matches the feature property named by
+// match_property (default "code").
+func (s *VillageService) UploadBoundaries(c *fiber.Ctx) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "GeoJSON file required",
+		})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: "Failed to open file",
+		})
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: "Failed to read file",
+		})
+	}
+
+	features, err := helper.ParseGeoJSONFeatures(data)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	matchProperty := c.FormValue("match_property", "code")
+	var updated []string
+	var failed []fiber.Map
+
+	for i, feature := range features {
+		value, ok := feature.Properties[matchProperty]
+		if !ok || value == nil {
+			failed = append(failed, fiber.Map{"feature": i, "message": "missing property " + matchProperty})
+			continue
+		}
+		code := helper.PropertyString(value)
+
+		village, err := s.villageRepo.GetByCode(code)
+		if err != nil {
+			failed = append(failed, fiber.Map{"feature": i, "code": code, "message": "village not found"})
+			continue
+		}
+
+		if err := s.setBoundary(village.ID, feature.Geometry); err != nil {
+			failed = append(failed, fiber.Map{"feature": i, "code": code, "message": err.Error()})
+			continue
+		}
+		updated = append(updated, code)
+	}
+
+	if len(failed) > 0 {
+		return c.Status(fiber.StatusPartialContent).JSON(model.Response{
+			Success: false,
+			Message: "Some boundaries failed to import",
+			Data: fiber.Map{
+				"updated": updated,
+				"failed":  failed,
+			},
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Boundaries imported successfully",
+		Data: fiber.Map{
+			"updated": updated,
+		},
+	})
+}
+
+// UpdateBoundary replaces one village's boundary with the Feature or
+// geometry in the request body.
+func (s *VillageService) UpdateBoundary(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	if _, err := s.villageRepo.GetByID(uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Village not found",
+		})
+	}
+
+	features, err := helper.ParseGeoJSONFeatures(c.Body())
+	if err != nil || len(features) != 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Body must be a single GeoJSON Feature or geometry",
+		})
+	}
+
+	if err := s.setBoundary(uint(id), features[0].Geometry); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	village, _ := s.villageRepo.GetByID(uint(id))
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Village boundary updated successfully",
+		Data:    village,
+	})
+}
+
+func (s *VillageService) archiveInto(c *fiber.Ctx, id uint, targetStr string) error {
+	targetID, err := strconv.ParseUint(targetStr, 10, 32)
+	if err != nil || uint(targetID) == id {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Invalid target_village_id",
+		})
+	}
+
+	target, err := s.villageRepo.GetByID(uint(targetID))
+	if err != nil || target.ArchivedAt != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+			Success: false,
+			Message: "Target village not found",
+		})
+	}
+
+	refs, err := s.villageRepo.CountReferences(id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	if err := s.villageRepo.ArchiveInto(id, target.ID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Village archived and merged into " + target.Name,
+		Data: fiber.Map{
+			"target_village_id": target.ID,
+			"moved":             refs,
+		},
+	})
+}
+
+func (s *VillageService) setBoundary(id uint, geometry json.RawMessage) error {
+	lat, lng, err := helper.GeometryCentroid(geometry)
+	if err != nil {
+		return err
+	}
+	return s.villageRepo.SetBoundary(id, string(geometry), lat, lng)
+}
+
+// GetStats returns the demographic breakdown of one village.
+func (s *VillageService) GetStats(c *fiber.Ctx) error {
+	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+	villageID := uint(id)
+	if _, err := s.villageRepo.GetByID(villageID); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			Success: false,
+			Message: "Village not found",
+		})
+	}
+
+	stats, err := s.villageRepo.GetStats(&villageID, statsSince(c))
+	if err != nil || len(stats) == 0 {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: "Failed to load village statistics",
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Village statistics retrieved successfully",
+		Data:    stats[0],
+	})
+}
+
+// GetStatsComparison returns the same breakdown for every active village
+// so they can be compared side by side.
+func (s *VillageService) GetStatsComparison(c *fiber.Ctx) error {
+	stats, err := s.villageRepo.GetStats(nil, statsSince(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(model.Response{
+		Success: true,
+		Message: "Village statistics retrieved successfully",
+		Data:    stats,
+	})
+}
+
+// statsSince is the first day of the oldest registration month to report,
+// from ?months= (default 12, including the current month).
+func statsSince(c *fiber.Ctx) time.Time {
+	months := helper.AtoiDefault(c.Query("months"), 12)
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, 1-months, 0)
+}
+
 func (s *VillageService) Create(c *fiber.Ctx) error {
 	var req model.CreateVillageRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -68,12 +349,22 @@ func (s *VillageService) Create(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.DistrictCode != nil {
+		if _, err := s.regionRepo.GetDistrict(*req.DistrictCode); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "District not found",
+			})
+		}
+	}
+
 	village := &model.Village{
-		Name:        req.Name,
-		Code:        req.Code,
-		Description: req.Description,
-		Color:       helper.GetStringValue(req.Color, "#3B82F6"),
-		IsActive:    helper.GetBoolValue(req.IsActive, true),
+		Name:         req.Name,
+		Code:         req.Code,
+		Description:  req.Description,
+		Color:        helper.GetStringValue(req.Color, "#3B82F6"),
+		IsActive:     helper.GetBoolValue(req.IsActive, true),
+		DistrictCode: req.DistrictCode,
 	}
 
 	if err := s.villageRepo.Create(village); err != nil {
@@ -109,12 +400,22 @@ func (s *VillageService) Update(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.DistrictCode != nil {
+		if _, err := s.regionRepo.GetDistrict(*req.DistrictCode); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "District not found",
+			})
+		}
+	}
+
 	updateData := &model.Village{
-		Name:        helper.GetStringValue(&req.Name, existing.Name),
-		Code:        helper.GetStringValue(&req.Code, existing.Code),
-		Description: helper.GetStringPointer(req.Description, existing.Description),
-		Color:       helper.GetStringValue(req.Color, existing.Color),
-		IsActive:    helper.GetBoolValue(req.IsActive, existing.IsActive),
+		Name:         helper.GetStringValue(&req.Name, existing.Name),
+		Code:         helper.GetStringValue(&req.Code, existing.Code),
+		Description:  helper.GetStringPointer(req.Description, existing.Description),
+		Color:        helper.GetStringValue(req.Color, existing.Color),
+		IsActive:     helper.GetBoolValue(req.IsActive, existing.IsActive),
+		DistrictCode: helper.GetStringPointer(req.DistrictCode, existing.DistrictCode),
 	}
 
 	if err := s.villageRepo.Update(uint(id), updateData); err != nil {
@@ -131,6 +432,9 @@ func (s *VillageService) Update(c *fiber.Ctx) error {
 	})
 }
 
+// Delete only removes a village nothing points at. A village still in use
+// needs target_village_id, which moves everything there and archives the
+// village instead.
 func (s *VillageService) Delete(c *fiber.Ctx) error {
 	id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
 	
@@ -141,6 +445,25 @@ func (s *VillageService) Delete(c *fiber.Ctx) error {
 		})
 	}
 
+	if targetStr := c.Query("target_village_id"); targetStr != "" {
+		return s.archiveInto(c, uint(id), targetStr)
+	}
+
+	refs, err := s.villageRepo.CountReferences(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if refs.Total() > 0 {
+		return c.Status(fiber.StatusConflict).JSON(model.Response{
+			Success: false,
+			Message: "Village is still in use, pass target_village_id to move its members and archive it",
+			Data:    refs,
+		})
+	}
+
 	if err := s.villageRepo.Delete(uint(id)); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
 			Success: false,
@@ -7,7 +7,8 @@ import (
 
 func CreateApp() *fiber.App {
 	app := fiber.New(fiber.Config{
-		AppName: AppConfig.AppName,
+		AppName:   AppConfig.AppName,
+		BodyLimit: 10 * 1024 * 1024, // room for photo and spreadsheet uploads
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
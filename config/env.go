@@ -21,6 +21,27 @@ type Config struct {
 	JWTExpire  string
 	LogLevel   string
 	LogPath    string
+
+	TrashRetentionDays string
+	UploadPath         string
+	UploadURL          string
+	CardValidityYears  string
+	CardReminderDays   string
+	SensitiveDataRoles string
+
+	MemberNumberFormat    string
+	MemberNumberSeqDigits string
+	MemberRegionCode      string
+
+	InvitationURL         string
+	InvitationExpiryHours string
+
+	TicketReopenDays  string
+	TicketOverdueDays string
+
+	TicketFirstResponseHours string
+	TicketResolutionHours    string
+	TicketSupervisorID       string
 }
 
 var AppConfig *Config
@@ -45,6 +66,27 @@ func LoadEnv() {
 		JWTExpire:  getEnv("JWT_EXPIRE", ""),
 		LogLevel:   getEnv("LOG_LEVEL", ""),
 		LogPath:    getEnv("LOG_FILE_PATH", ""),
+
+		TrashRetentionDays: getEnv("TRASH_RETENTION_DAYS", "30"),
+		UploadPath:         getEnv("UPLOAD_PATH", "./uploads"),
+		UploadURL:          getEnv("UPLOAD_URL", "/uploads"),
+		CardValidityYears:  getEnv("CARD_VALIDITY_YEARS", "5"),
+		CardReminderDays:   getEnv("CARD_REMINDER_DAYS", "30"),
+		SensitiveDataRoles: getEnv("SENSITIVE_DATA_ROLES", "1"),
+
+		MemberNumberFormat:    getEnv("MEMBER_NUMBER_FORMAT", "{region}{village}{year}{seq}"),
+		MemberNumberSeqDigits: getEnv("MEMBER_NUMBER_SEQ_DIGITS", "5"),
+		MemberRegionCode:      getEnv("MEMBER_REGION_CODE", "3578"),
+
+		InvitationURL:         getEnv("INVITATION_URL", "/invite"),
+		InvitationExpiryHours: getEnv("INVITATION_EXPIRY_HOURS", "72"),
+
+		TicketReopenDays:  getEnv("TICKET_REOPEN_DAYS", "7"),
+		TicketOverdueDays: getEnv("TICKET_OVERDUE_DAYS", "3"),
+
+		TicketFirstResponseHours: getEnv("TICKET_FIRST_RESPONSE_HOURS", "24"),
+		TicketResolutionHours:    getEnv("TICKET_RESOLUTION_HOURS", "168"),
+		TicketSupervisorID:       getEnv("TICKET_SUPERVISOR_ID", ""),
 	}
 }
 
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"log"
+	"arek-muhammadiyah-be/app/model"
+)
+
+// Migrate keeps the schema in sync with the models, adding new tables and
+// columns. It never drops anything.
+func Migrate() {
+	err := DB.AutoMigrate(
+		&model.Role{},
+		&model.Province{},
+		&model.Regency{},
+		&model.District{},
+		&model.Branch{},
+		&model.Village{},
+		&model.Household{},
+		&model.User{},
+		&model.MemberSequence{},
+		&model.Invitation{},
+		&model.Category{},
+		&model.Article{},
+		&model.Ticket{},
+		&model.TicketMessage{},
+		&model.TicketStatusHistory{},
+		&model.TicketAssignmentRule{},
+		&model.Document{},
+		&model.ProfileChangeRequest{},
+		&model.CardStatusHistory{},
+		&model.CardRenewal{},
+		&model.Notification{},
+		&model.DuesSchedule{},
+		&model.DuesPayment{},
+		&model.ErasureRequest{},
+		&model.Position{},
+		&model.OfficerTerm{},
+		&model.Menu{},
+		&model.SubMenu{},
+		&model.RoleMenu{},
+	)
+	if err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	log.Println("Database migrated successfully")
+}
@@ -0,0 +1,20 @@
+package helper
+
+import (
+	"arek-muhammadiyah-be/config"
+	"time"
+)
+
+// CardExpiry returns when a card issued at issuedAt stops being valid.
+func CardExpiry(issuedAt time.Time) time.Time {
+	return issuedAt.AddDate(AtoiDefault(config.AppConfig.CardValidityYears, 5), 0, 0)
+}
+
+// CardIssueColumns are the extra user columns set when a card is printed.
+func CardIssueColumns(issuedAt time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"card_issued_at":        issuedAt,
+		"card_expires_at":       CardExpiry(issuedAt),
+		"card_reminder_sent_at": nil,
+	}
+}
@@ -1,11 +1,19 @@
 package helper
 
 import (
+	"bytes"
 	"encoding/csv"
+	"errors"
 	"io"
 	"arek-muhammadiyah-be/app/model"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
 )
 
 type CSVUserData struct {
@@ -16,35 +24,133 @@ type CSVUserData struct {
 	VillageID string `csv:"village_id"`
 }
 
+// ParseUsersFromFile picks the parser based on the uploaded file extension.
+// Sheet is only used for XLSX files; an empty sheet means the first one.
+func ParseUsersFromFile(filename string, reader io.Reader, sheet string) ([]model.CreateUserRequest, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx", ".xlsm":
+		return ParseUsersFromXLSX(reader, sheet)
+	case ".xls":
+		return nil, errors.New("legacy .xls files are not supported, save the file as .xlsx or .csv")
+	default:
+		return ParseUsersFromCSV(reader)
+	}
+}
+
 func ParseUsersFromCSV(reader io.Reader) ([]model.CreateUserRequest, error) {
-	csvReader := csv.NewReader(reader)
-	records, err := csvReader.ReadAll()
+	records, err := readCSVRecords(reader)
+	if err != nil {
+		return nil, err
+	}
+	return parseUserRecords(records), nil
+}
+
+func ParseUsersFromXLSX(reader io.Reader, sheet string) ([]model.CreateUserRequest, error) {
+	records, err := readXLSXRecords(reader, sheet)
+	if err != nil {
+		return nil, err
+	}
+	return parseUserRecords(records), nil
+}
+
+// readCSVRecords decodes the text and sniffs the delimiter before reading
+// every row, header included.
+func readCSVRecords(reader io.Reader) ([][]string, error) {
+	raw, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, err
 	}
 
+	data, err := decodeText(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	csvReader := csv.NewReader(bytes.NewReader(data))
+	csvReader.Comma = detectDelimiter(data)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	return csvReader.ReadAll()
+}
+
+// readXLSXRecords reads every row of the sheet, or of the first sheet when
+// sheet is empty.
+func readXLSXRecords(reader io.Reader, sheet string) ([][]string, error) {
+	file, err := excelize.OpenReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if sheet == "" {
+		sheet = file.GetSheetName(0)
+	}
+	if index, err := file.GetSheetIndex(sheet); err != nil || index == -1 {
+		return nil, errors.New("sheet " + sheet + " not found")
+	}
+
+	return file.GetRows(sheet)
+}
+
+// userColumns is the legacy positional layout, used when the header row
+// does not name its columns.
+var userColumns = []string{"id", "name", "nik", "address", "village_id"}
+
+func parseUserRecords(records [][]string) []model.CreateUserRequest {
 	var users []model.CreateUserRequest
-	
+	if len(records) == 0 {
+		return users
+	}
+
+	columns := headerColumns(records[0])
+
 	// Skip header row
-	for i, record := range records {
-		if i == 0 {
+	for i, record := range records[1:] {
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
 			continue
 		}
-		
-		if len(record) < 5 {
-			continue
+
+		value := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		optional := func(name string) *string {
+			if v := value(name); v != "" {
+				return &v
+			}
+			return nil
 		}
 
+		// Rows without an id get a generated member number. Rows without a
+		// name are kept so the import reports them as failed
 		user := model.CreateUserRequest{
-			ID:       strings.TrimSpace(record[0]),
-			Name:     strings.TrimSpace(record[1]),
+			Row:      i + 2,
+			ID:       value("id"),
+			Name:     value("name"),
 			Password: GenerateRandomString(8), // Generate random password
-			NIK:      &record[2],
-			Address:  &record[3],
+			NIK:      optional("nik"),
+			Address:  optional("address"),
+			Telp:     optional("telp"),
+
+			ReferredBy:   optional("referred_by"),
+			ReferralCode: optional("referral_code"),
+			UserProfileRequest: model.UserProfileRequest{
+				BirthPlace:    optional("birth_place"),
+				BirthDate:     optional("birth_date"),
+				Gender:        optional("gender"),
+				Occupation:    optional("occupation"),
+				Education:     optional("education"),
+				MaritalStatus: optional("marital_status"),
+				Ranting:       optional("ranting"),
+				Cabang:        optional("cabang"),
+				Daerah:        optional("daerah"),
+			},
 		}
 
 		// Parse village ID
-		if villageIDStr := strings.TrimSpace(record[4]); villageIDStr != "" {
+		if villageIDStr := value("village_id"); villageIDStr != "" {
 			if villageID, err := strconv.ParseUint(villageIDStr, 10, 32); err == nil {
 				villageIDUint := uint(villageID)
 				user.VillageID = &villageIDUint
@@ -54,5 +160,78 @@ func ParseUsersFromCSV(reader io.Reader) ([]model.CreateUserRequest, error) {
 		users = append(users, user)
 	}
 
-	return users, nil
-}
\ No newline at end of file
+	return users
+}
+
+// headerColumns maps column names to their index. Files whose header does
+// not contain a name column fall back to the legacy column order.
+func headerColumns(header []string) map[string]int {
+	columns := namedColumns(header)
+	if _, hasName := columns["name"]; hasName {
+		return columns
+	}
+
+	columns = make(map[string]int)
+	for i, name := range userColumns {
+		columns[name] = i
+	}
+	return columns
+}
+
+// namedColumns maps normalized header names such as "Village ID" to
+// village_id onto their column index.
+func namedColumns(header []string) map[string]int {
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		name = strings.ReplaceAll(name, " ", "_")
+		columns[name] = i
+	}
+	return columns
+}
+
+// decodeText strips byte order marks and converts UTF-16 or Windows-1252
+// input (what Excel produces on Indonesian Windows installs) to UTF-8.
+func decodeText(raw []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(raw, []byte{0xEF, 0xBB, 0xBF}):
+		return raw[3:], nil
+	case bytes.HasPrefix(raw, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder().Bytes(raw)
+	case bytes.HasPrefix(raw, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder().Bytes(raw)
+	case utf8.Valid(raw):
+		return raw, nil
+	default:
+		return charmap.Windows1252.NewDecoder().Bytes(raw)
+	}
+}
+
+// detectDelimiter looks at the header line and picks whichever of comma,
+// semicolon or tab occurs most often outside quotes.
+func detectDelimiter(data []byte) rune {
+	header := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		header = data[:i]
+	}
+
+	counts := map[rune]int{',': 0, ';': 0, '\t': 0}
+	inQuotes := false
+	for _, r := range string(header) {
+		if r == '"' {
+			inQuotes = !inQuotes
+			continue
+		}
+		if _, ok := counts[r]; ok && !inQuotes {
+			counts[r]++
+		}
+	}
+
+	delimiter := ','
+	for _, r := range []rune{';', '\t'} {
+		if counts[r] > counts[delimiter] {
+			delimiter = r
+		}
+	}
+	return delimiter
+}
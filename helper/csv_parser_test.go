@@ -0,0 +1,119 @@
+package helper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []byte
+		want string
+	}{
+		{"plain utf-8", []byte("id,name\n1,Budi\n"), "id,name\n1,Budi\n"},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, "id,name\n"...), "id,name\n"},
+		{"utf-16le bom", []byte{0xFF, 0xFE, 'i', 0, 'd', 0, ',', 0, 0xE9, 0}, "id,é"},
+		{"utf-16be bom", []byte{0xFE, 0xFF, 0, 'i', 0, 'd', 0, ',', 0, 0xE9}, "id,é"},
+		{"windows-1252", []byte("name\nJos\xe9 \x96 Surabaya\n"), "name\nJosé – Surabaya\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeText(tt.raw)
+			if err != nil {
+				t.Fatalf("decodeText: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectDelimiter(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want rune
+	}{
+		{"comma", "id,name,nik\n1,Budi,123\n", ','},
+		{"semicolon", "id;name;nik\n1;Budi;123\n", ';'},
+		{"tab", "id\tname\tnik\n1\tBudi\t123\n", '\t'},
+		{"header only", "id;name;nik", ';'},
+		{"only the header line counts", "id;name\n1,2,3,4,5\n", ';'},
+		{"commas inside quotes", "\"name, full\";\"address, street\";nik\n", ';'},
+		{"no delimiter", "name\nBudi\n", ','},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDelimiter([]byte(tt.data)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeaderColumns(t *testing.T) {
+	legacy := map[string]int{"id": 0, "name": 1, "nik": 2, "address": 3, "village_id": 4}
+
+	tests := []struct {
+		name   string
+		header []string
+		want   map[string]int
+	}{
+		{
+			"named header",
+			[]string{"Name", "NIK", "Village ID"},
+			map[string]int{"name": 0, "nik": 1, "village_id": 2},
+		},
+		{
+			"named header with padding",
+			[]string{" id ", "  NAME", "telp"},
+			map[string]int{"id": 0, "name": 1, "telp": 2},
+		},
+		{"no name column falls back", []string{"no", "nama", "nik", "alamat", "desa"}, legacy},
+		{"empty header falls back", nil, legacy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := headerColumns(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUsersFromCSV(t *testing.T) {
+	data := "\xEF\xBB\xBFname;nik;village_id\nBudi;3578;12\n;3579;12\n;;\nSiti;;\n"
+
+	users, err := ParseUsersFromCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseUsersFromCSV: %v", err)
+	}
+
+	// The blank row is dropped, the nameless one is kept for reporting
+	var got []string
+	for _, user := range users {
+		got = append(got, user.Name)
+	}
+	if want := []string{"Budi", "", "Siti"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("names: got %q, want %q", got, want)
+	}
+
+	if users[1].Row != 3 {
+		t.Errorf("nameless row: got row %d, want 3", users[1].Row)
+	}
+	if users[0].NIK == nil || *users[0].NIK != "3578" {
+		t.Errorf("nik: got %v, want 3578", users[0].NIK)
+	}
+	if users[0].VillageID == nil || *users[0].VillageID != 12 {
+		t.Errorf("village_id: got %v, want 12", users[0].VillageID)
+	}
+	if users[2].NIK != nil {
+		t.Errorf("empty nik: got %q, want nil", *users[2].NIK)
+	}
+}
@@ -0,0 +1,101 @@
+package helper
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+type geoJSONObject struct {
+	Type        string                 `json:"type"`
+	Features    []model.GeoJSONFeature `json:"features"`
+	Geometry    json.RawMessage        `json:"geometry"`
+	Properties  map[string]interface{} `json:"properties"`
+	Coordinates json.RawMessage        `json:"coordinates"`
+}
+
+// ParseGeoJSONFeatures accepts a FeatureCollection, a single Feature or a
+// bare geometry and returns it as a list of features.
+func ParseGeoJSONFeatures(data []byte) ([]model.GeoJSONFeature, error) {
+	var obj geoJSONObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+
+	switch obj.Type {
+	case "FeatureCollection":
+		return obj.Features, nil
+	case "Feature":
+		return []model.GeoJSONFeature{{Type: "Feature", Geometry: obj.Geometry, Properties: obj.Properties}}, nil
+	case "Polygon", "MultiPolygon":
+		return []model.GeoJSONFeature{{Type: "Feature", Geometry: json.RawMessage(data)}}, nil
+	}
+	return nil, fmt.Errorf("unsupported GeoJSON type %q", obj.Type)
+}
+
+// GeometryCentroid validates a Polygon or MultiPolygon geometry and
+// returns the area-weighted centroid of its outer rings as lat, lng.
+// Village boundaries are small enough to treat coordinates as planar.
+func GeometryCentroid(geometry json.RawMessage) (float64, float64, error) {
+	var obj geoJSONObject
+	if err := json.Unmarshal(geometry, &obj); err != nil {
+		return 0, 0, fmt.Errorf("invalid geometry: %w", err)
+	}
+
+	var polygons [][][][]float64
+	switch obj.Type {
+	case "Polygon":
+		var polygon [][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &polygon); err != nil {
+			return 0, 0, fmt.Errorf("invalid Polygon coordinates: %w", err)
+		}
+		polygons = [][][][]float64{polygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(obj.Coordinates, &polygons); err != nil {
+			return 0, 0, fmt.Errorf("invalid MultiPolygon coordinates: %w", err)
+		}
+	default:
+		return 0, 0, fmt.Errorf("geometry must be a Polygon or MultiPolygon, got %q", obj.Type)
+	}
+
+	var area, cx, cy, sumX, sumY float64
+	var points int
+	for _, polygon := range polygons {
+		if len(polygon) == 0 || len(polygon[0]) < 4 {
+			return 0, 0, errors.New("polygon ring needs at least four positions")
+		}
+		ring := polygon[0]
+		for i := 0; i < len(ring)-1; i++ {
+			if len(ring[i]) < 2 || len(ring[i+1]) < 2 {
+				return 0, 0, errors.New("position needs longitude and latitude")
+			}
+			x0, y0 := ring[i][0], ring[i][1]
+			x1, y1 := ring[i+1][0], ring[i+1][1]
+			cross := x0*y1 - x1*y0
+			area += cross
+			cx += (x0 + x1) * cross
+			cy += (y0 + y1) * cross
+			sumX += x0
+			sumY += y0
+			points++
+		}
+	}
+
+	// Degenerate rings fall back to the mean of their positions
+	if area == 0 {
+		return sumY / float64(points), sumX / float64(points), nil
+	}
+	return cy / (3 * area), cx / (3 * area), nil
+}
+
+// PropertyString formats a feature property as text. JSON numbers decode
+// as float64, so they are written out in full rather than in exponent
+// form, keeping numeric codes such as 3578011001 intact.
+func PropertyString(value interface{}) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
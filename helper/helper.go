@@ -6,6 +6,7 @@ import (
 	"math/big"
 	"arek-muhammadiyah-be/app/model"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -75,12 +76,38 @@ func GetBoolValue(newVal *bool, defaultVal bool) bool {
 	return defaultVal
 }
 
+func GetIntValue(newVal *int, defaultVal int) int {
+	if newVal != nil {
+		return *newVal
+	}
+	return defaultVal
+}
+
 func GenerateRandomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	return randomFromCharset("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789", length)
+}
+
+// GenerateReferralCode returns an uppercase code without look-alike
+// characters such as 0/O and 1/I, since members read it out loud.
+func GenerateReferralCode() string {
+	return randomFromCharset("ABCDEFGHJKLMNPQRSTUVWXYZ23456789", 8)
+}
+
+func randomFromCharset(charset string, length int) string {
 	b := make([]byte, length)
 	for i := range b {
 		num, _ := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
 		b[i] = charset[num.Int64()]
 	}
 	return string(b)
-}
\ No newline at end of file
+}
+
+// AtoiDefault parses a numeric config value, falling back to defaultVal
+// when it is missing, invalid or not positive.
+func AtoiDefault(value string, defaultVal int) int {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return defaultVal
+	}
+	return n
+}
@@ -0,0 +1,91 @@
+package helper
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	MaxPhotoSize   = 8 << 20 // 8 MB
+	maxPhotoPixels = 50_000_000
+
+	cardPhotoWidth  = 600
+	cardPhotoHeight = 800
+	thumbPhotoSize  = 200
+)
+
+// ProcessPhoto decodes a JPEG, PNG or WebP upload and returns card and
+// thumbnail JPEGs. The EXIF orientation of JPEGs is applied first, since
+// re-encoding drops the metadata that told viewers to rotate them.
+func ProcessPhoto(reader io.Reader) ([]byte, []byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, MaxPhotoSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) > MaxPhotoSize {
+		return nil, nil, errors.New("photo must be at most 8 MB")
+	}
+
+	// Check dimensions before decoding so a small file cannot claim a huge canvas
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png" && format != "webp") {
+		return nil, nil, errors.New("unsupported image, upload a JPEG, PNG or WebP file")
+	}
+	if cfg.Width*cfg.Height > maxPhotoPixels {
+		return nil, nil, errors.New("photo dimensions are too large")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, errors.New("unsupported image, upload a JPEG, PNG or WebP file")
+	}
+	if format == "jpeg" {
+		src = applyOrientation(src, jpegOrientation(data))
+	}
+
+	card, err := encodeJPEG(cropResize(src, cardPhotoWidth, cardPhotoHeight))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	thumb, err := encodeJPEG(cropResize(src, thumbPhotoSize, thumbPhotoSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return card, thumb, nil
+}
+
+// cropResize center-crops src to the target aspect ratio and scales it to
+// width x height.
+func cropResize(src image.Image, width, height int) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	cropW, cropH := srcW, srcW*height/width
+	if cropH > srcH {
+		cropW, cropH = srcH*width/height, srcH
+	}
+	x0 := bounds.Min.X + (srcW-cropW)/2
+	y0 := bounds.Min.Y + (srcH-cropH)/2
+	crop := image.Rect(x0, y0, x0+cropW, y0+cropH)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+	return dst
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,31 @@
+package helper
+
+import (
+	"arek-muhammadiyah-be/config"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"time"
+)
+
+// GenerateInvitationToken returns a new token and the hash that is stored
+// for it.
+func GenerateInvitationToken() (string, string) {
+	token := GenerateRandomString(32)
+	return token, HashInvitationToken(token)
+}
+
+func HashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func InvitationExpiry(sentAt time.Time) time.Time {
+	return sentAt.Add(time.Duration(AtoiDefault(config.AppConfig.InvitationExpiryHours, 72)) * time.Hour)
+}
+
+// InvitationLink is the page on INVITATION_URL where the member sets a
+// password.
+func InvitationLink(token string) string {
+	return config.AppConfig.InvitationURL + "?token=" + url.QueryEscape(token)
+}
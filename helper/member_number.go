@@ -0,0 +1,31 @@
+package helper
+
+import (
+	"arek-muhammadiyah-be/config"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FormatMemberNumber fills the MEMBER_NUMBER_FORMAT template. Supported
+// placeholders are {region}, {village}, {year}, {yy} and {seq}, where
+// {seq} is zero padded to MEMBER_NUMBER_SEQ_DIGITS. A format without
+// {seq} gets it appended, since the sequence is what keeps numbers unique.
+func FormatMemberNumber(villageCode string, year, seq int) string {
+	format := config.AppConfig.MemberNumberFormat
+	if !strings.Contains(format, "{seq}") {
+		format += "{seq}"
+	}
+	if villageCode == "" {
+		villageCode = "00"
+	}
+	digits := AtoiDefault(config.AppConfig.MemberNumberSeqDigits, 5)
+
+	return strings.NewReplacer(
+		"{region}", config.AppConfig.MemberRegionCode,
+		"{village}", strings.ToUpper(villageCode),
+		"{year}", strconv.Itoa(year),
+		"{yy}", fmt.Sprintf("%02d", year%100),
+		"{seq}", fmt.Sprintf("%0*d", digits, seq),
+	).Replace(format)
+}
@@ -0,0 +1,126 @@
+package helper
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/config"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var userType = reflect.TypeOf(model.User{})
+var categoryType = reflect.TypeOf(model.Category{})
+var modelPkgPath = userType.PkgPath()
+
+// CanViewSensitive reports whether a role may see other members' NIK,
+// phone number and address. Allowed roles come from SENSITIVE_DATA_ROLES.
+func CanViewSensitive(roleID *uint) bool {
+	if roleID == nil {
+		return false
+	}
+	for _, id := range strings.Split(config.AppConfig.SensitiveDataRoles, ",") {
+		if allowed, err := strconv.ParseUint(strings.TrimSpace(id), 10, 32); err == nil && uint(allowed) == *roleID {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskSensitive returns data with the NIK, Telp and Address of every
+// embedded User masked, except the caller's own record, and with category
+// supervisors hidden. Callers with a role allowed by CanViewSensitive get
+// data back unchanged.
+func MaskSensitive(c *fiber.Ctx, data interface{}) interface{} {
+	viewerID, _ := c.Locals("user_id").(string)
+	roleID, _ := c.Locals("role_id").(*uint)
+	if data == nil || CanViewSensitive(roleID) {
+		return data
+	}
+
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Ptr && value.Kind() != reflect.Slice && value.Kind() != reflect.Map {
+		// Copy into an addressable value so struct fields can be set
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+		value = copied
+	}
+
+	maskValue(value, viewerID, map[uintptr]bool{})
+	return value.Interface()
+}
+
+func maskValue(value reflect.Value, viewerID string, seen map[uintptr]bool) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() || seen[value.Pointer()] {
+			return
+		}
+		seen[value.Pointer()] = true
+		maskValue(value.Elem(), viewerID, seen)
+	case reflect.Interface:
+		if value.IsNil() {
+			return
+		}
+		inner := value.Elem()
+		if inner.Kind() == reflect.Struct && value.CanSet() {
+			copied := reflect.New(inner.Type()).Elem()
+			copied.Set(inner)
+			maskValue(copied, viewerID, seen)
+			value.Set(copied)
+			return
+		}
+		maskValue(inner, viewerID, seen)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			maskValue(value.Index(i), viewerID, seen)
+		}
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			item := value.MapIndex(key)
+			copied := reflect.New(item.Type()).Elem()
+			copied.Set(item)
+			maskValue(copied, viewerID, seen)
+			value.SetMapIndex(key, copied)
+		}
+	case reflect.Struct:
+		if value.Type().PkgPath() != modelPkgPath {
+			return
+		}
+		if value.Type() == userType && value.CanAddr() {
+			maskUser(value.Addr().Interface().(*model.User), viewerID)
+		}
+		if value.Type() == categoryType && value.CanAddr() {
+			value.Addr().Interface().(*model.Category).SupervisorID = nil
+		}
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).IsExported() {
+				maskValue(value.Field(i), viewerID, seen)
+			}
+		}
+	}
+}
+
+func maskUser(user *model.User, viewerID string) {
+	if viewerID != "" && user.ID == viewerID {
+		return
+	}
+	user.NIK = maskTail(user.NIK, 4)
+	user.Telp = maskTail(user.Telp, 3)
+	user.Address = nil
+}
+
+// maskTail replaces all but the last n characters with asterisks.
+func maskTail(value *string, n int) *string {
+	if value == nil || *value == "" {
+		return value
+	}
+	runes := []rune(*value)
+	if len(runes) <= n {
+		masked := strings.Repeat("*", len(runes))
+		return &masked
+	}
+	masked := strings.Repeat("*", len(runes)-n) + string(runes[len(runes)-n:])
+	return &masked
+}
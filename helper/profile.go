@@ -0,0 +1,91 @@
+package helper
+
+import (
+	"errors"
+	"arek-muhammadiyah-be/app/model"
+	"strings"
+	"time"
+)
+
+var dateLayouts = []string{"2006-01-02", "02/01/2006", "02-01-2006", "2/1/2006"}
+
+// ParseDate accepts ISO dates as well as the day-first formats used in
+// Indonesian spreadsheets.
+func ParseDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, errors.New("invalid date: " + value)
+}
+
+func NormalizeGender(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "male", "l", "laki-laki", "laki laki":
+		return model.GenderMale, nil
+	case "female", "p", "perempuan", "wanita":
+		return model.GenderFemale, nil
+	}
+	return "", errors.New("invalid gender: " + value)
+}
+
+func NormalizeMaritalStatus(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "single", "belum kawin", "belum menikah":
+		return model.MaritalStatusSingle, nil
+	case "married", "kawin", "menikah":
+		return model.MaritalStatusMarried, nil
+	case "divorced", "cerai hidup":
+		return model.MaritalStatusDivorced, nil
+	case "widowed", "cerai mati":
+		return model.MaritalStatusWidowed, nil
+	}
+	return "", errors.New("invalid marital status: " + value)
+}
+
+// ApplyUserProfile copies the profile fields that are set in req onto user,
+// normalizing gender, marital status and birth date along the way.
+func ApplyUserProfile(user *model.User, req *model.UserProfileRequest) error {
+	if req.BirthPlace != nil {
+		user.BirthPlace = req.BirthPlace
+	}
+	if req.BirthDate != nil && *req.BirthDate != "" {
+		birthDate, err := ParseDate(*req.BirthDate)
+		if err != nil {
+			return err
+		}
+		user.BirthDate = birthDate
+	}
+	if req.Gender != nil && *req.Gender != "" {
+		gender, err := NormalizeGender(*req.Gender)
+		if err != nil {
+			return err
+		}
+		user.Gender = &gender
+	}
+	if req.Occupation != nil {
+		user.Occupation = req.Occupation
+	}
+	if req.Education != nil {
+		user.Education = req.Education
+	}
+	if req.MaritalStatus != nil && *req.MaritalStatus != "" {
+		status, err := NormalizeMaritalStatus(*req.MaritalStatus)
+		if err != nil {
+			return err
+		}
+		user.MaritalStatus = &status
+	}
+	if req.Ranting != nil {
+		user.Ranting = req.Ranting
+	}
+	if req.Cabang != nil {
+		user.Cabang = req.Cabang
+	}
+	if req.Daerah != nil {
+		user.Daerah = req.Daerah
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package helper
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var regionCodePatterns = map[string]*regexp.Regexp{
+	model.RegionLevelProvince: regexp.MustCompile(`^\d{2}$`),
+	model.RegionLevelRegency:  regexp.MustCompile(`^\d{2}\.\d{2}$`),
+	model.RegionLevelDistrict: regexp.MustCompile(`^\d{2}\.\d{2}\.\d{2}$`),
+}
+
+// ValidateRegionCode checks a Kemendagri code for the given level and
+// that it sits under its parent, e.g. regency 35.78 under province 35.
+func ValidateRegionCode(level, code, parentCode string) error {
+	if !regionCodePatterns[level].MatchString(code) {
+		return fmt.Errorf("invalid %s code %q", level, code)
+	}
+	if level != model.RegionLevelProvince && !strings.HasPrefix(code, parentCode+".") {
+		return fmt.Errorf("%s code %s does not belong to %s", level, code, parentCode)
+	}
+	return nil
+}
+
+// ParseRegionFilter reads province_code, regency_code and district_code
+// from the query string.
+func ParseRegionFilter(c *fiber.Ctx) model.RegionFilter {
+	return model.RegionFilter{
+		ProvinceCode: strings.TrimSpace(c.Query("province_code")),
+		RegencyCode:  strings.TrimSpace(c.Query("regency_code")),
+		DistrictCode: strings.TrimSpace(c.Query("district_code")),
+	}
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"arek-muhammadiyah-be/config"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Storage saves uploaded files and returns the URL they are served from.
+type Storage interface {
+	Save(path string, data []byte) (string, error)
+	Delete(path string) error
+	// PathOf maps a URL returned by Save back to the stored path. It
+	// reports false for URLs that do not point into the storage.
+	PathOf(url string) (string, bool)
+}
+
+// LocalStorage writes files under a directory that the app serves
+// statically at BaseURL.
+type LocalStorage struct {
+	Root    string
+	BaseURL string
+}
+
+func NewStorage() Storage {
+	return &LocalStorage{
+		Root:    config.AppConfig.UploadPath,
+		BaseURL: config.AppConfig.UploadURL,
+	}
+}
+
+func (s *LocalStorage) Save(path string, data []byte) (string, error) {
+	fullPath := filepath.Join(s.Root, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(fullPath, data, 0644); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(s.BaseURL, "/") + "/" + path, nil
+}
+
+func (s *LocalStorage) Delete(path string) error {
+	err := os.Remove(filepath.Join(s.Root, filepath.FromSlash(path)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStorage) PathOf(url string) (string, bool) {
+	prefix := strings.TrimRight(s.BaseURL, "/") + "/"
+	if !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+	path := strings.TrimPrefix(url, prefix)
+	// Older photo URLs carry a cache-busting query
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" || strings.Contains(path, "..") {
+		return "", false
+	}
+	return path, true
+}
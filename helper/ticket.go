@@ -0,0 +1,54 @@
+package helper
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"arek-muhammadiyah-be/config"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// IsStaff reports whether the caller has the admin role that AdminOnly
+// lets through, which is who answers tickets.
+func IsStaff(c *fiber.Ctx) bool {
+	roleID, _ := c.Locals("role_id").(*uint)
+	return IsStaffRole(roleID)
+}
+
+func IsStaffRole(roleID *uint) bool {
+	return roleID != nil && *roleID == 1
+}
+
+// TicketReopenDeadline returns until when the reporter may reopen a ticket
+// resolved at resolvedAt.
+func TicketReopenDeadline(resolvedAt time.Time) time.Time {
+	return resolvedAt.AddDate(0, 0, AtoiDefault(config.AppConfig.TicketReopenDays, 7))
+}
+
+// TicketOverdueBefore returns the creation time before which a ticket that
+// is still open counts as overdue.
+func TicketOverdueBefore(now time.Time) time.Time {
+	return now.AddDate(0, 0, -AtoiDefault(config.AppConfig.TicketOverdueDays, 3))
+}
+
+// TicketDueDates returns when a ticket created at createdAt needs its first
+// response and its resolution, using the category targets when set.
+func TicketDueDates(createdAt time.Time, category *model.Category) (firstResponse, resolution time.Time) {
+	firstResponseHours := AtoiDefault(config.AppConfig.TicketFirstResponseHours, 24)
+	resolutionHours := AtoiDefault(config.AppConfig.TicketResolutionHours, 168)
+	if category != nil {
+		firstResponseHours = GetIntValue(category.FirstResponseHours, firstResponseHours)
+		resolutionHours = GetIntValue(category.ResolutionHours, resolutionHours)
+	}
+	return createdAt.Add(time.Duration(firstResponseHours) * time.Hour),
+		createdAt.Add(time.Duration(resolutionHours) * time.Hour)
+}
+
+// TicketSupervisor returns who breaches in the category escalate to, or an
+// empty string when nobody is configured.
+func TicketSupervisor(category *model.Category) string {
+	if category != nil && category.SupervisorID != nil && *category.SupervisorID != "" {
+		return *category.SupervisorID
+	}
+	return config.AppConfig.TicketSupervisorID
+}
@@ -0,0 +1,128 @@
+package helper
+
+import (
+	"arek-muhammadiyah-be/app/model"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ParseVillagesFromFile reads villages from CSV, XLSX or GeoJSON. Tabular
+// files need a header row with at least a code column; GeoJSON features
+// carry the same fields as properties and their geometry as boundary.
+func ParseVillagesFromFile(filename string, reader io.Reader, sheet string) ([]model.ImportVillageRequest, error) {
+	var records [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".geojson", ".json":
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		return parseVillageFeatures(data)
+	case ".xlsx", ".xlsm":
+		records, err = readXLSXRecords(reader, sheet)
+	case ".xls":
+		return nil, errors.New("legacy .xls files are not supported, save the file as .xlsx or .csv")
+	default:
+		records, err = readCSVRecords(reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parseVillageRecords(records)
+}
+
+func parseVillageRecords(records [][]string) ([]model.ImportVillageRequest, error) {
+	var villages []model.ImportVillageRequest
+	if len(records) == 0 {
+		return villages, nil
+	}
+
+	columns := namedColumns(records[0])
+	if _, ok := columns["code"]; !ok {
+		return nil, errors.New("header row must contain a code column")
+	}
+
+	for i, record := range records[1:] {
+		values := make(map[string]string)
+		for name, index := range columns {
+			if index < len(record) {
+				values[name] = strings.TrimSpace(record[index])
+			}
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		village, err := villageFromValues(values)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+		village.Row = i + 2
+		villages = append(villages, village)
+	}
+
+	return villages, nil
+}
+
+func parseVillageFeatures(data []byte) ([]model.ImportVillageRequest, error) {
+	features, err := ParseGeoJSONFeatures(data)
+	if err != nil {
+		return nil, err
+	}
+
+	villages := make([]model.ImportVillageRequest, 0, len(features))
+	for i, feature := range features {
+		values := make(map[string]string)
+		for name, value := range feature.Properties {
+			if value != nil {
+				values[strings.ToLower(name)] = strings.TrimSpace(PropertyString(value))
+			}
+		}
+
+		village, err := villageFromValues(values)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i+1, err)
+		}
+		village.Row = i + 1
+		if len(feature.Geometry) > 0 && string(feature.Geometry) != "null" {
+			village.Boundary = feature.Geometry
+		}
+		villages = append(villages, village)
+	}
+
+	return villages, nil
+}
+
+func villageFromValues(values map[string]string) (model.ImportVillageRequest, error) {
+	optional := func(name string) *string {
+		if v := values[name]; v != "" {
+			return &v
+		}
+		return nil
+	}
+
+	village := model.ImportVillageRequest{
+		Code:         values["code"],
+		Name:         optional("name"),
+		Description:  optional("description"),
+		Color:        optional("color"),
+		DistrictCode: optional("district_code"),
+	}
+
+	if active := values["is_active"]; active != "" {
+		isActive, err := strconv.ParseBool(strings.ToLower(active))
+		if err != nil {
+			return village, fmt.Errorf("invalid is_active %q", active)
+		}
+		village.IsActive = &isActive
+	}
+
+	return village, nil
+}
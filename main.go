@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"arek-muhammadiyah-be/app/job"
 	"arek-muhammadiyah-be/config"
 	"arek-muhammadiyah-be/database"
 	"arek-muhammadiyah-be/middleware"
@@ -14,6 +15,7 @@ func main() {
 
 	// Initialize database
 	database.ConnectDB()
+	database.Migrate()
 
 	// Create Fiber app
 	app := config.CreateApp()
@@ -24,6 +26,9 @@ func main() {
 	// Setup routes
 	route.Setup(app)
 
+	// Start background jobs
+	job.Start()
+
 	// Start server
 	log.Fatal(app.Listen(":8080"))
 }
\ No newline at end of file
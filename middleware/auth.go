@@ -1,14 +1,22 @@
 package middleware
 
 import (
+	"arek-muhammadiyah-be/app/repository"
 	"arek-muhammadiyah-be/helper/utils"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 func Authorization() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// Groups sharing a prefix, such as /api/me, each register this
+		// middleware; check the token and account status only once
+		if _, done := c.Locals("user_id").(string); done {
+			return c.Next()
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -26,6 +34,20 @@ func Authorization() fiber.Handler {
 			})
 		}
 
+		user, err := repository.NewUserRepository().GetAccountStatus(claims.UserID)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":   true,
+				"message": "Account not found",
+			})
+		}
+		if user.IsSuspended(time.Now()) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":   true,
+				"message": "Account is suspended",
+			})
+		}
+
 		c.Locals("user_id", claims.UserID)
 		c.Locals("role_id", claims.RoleID)
 		return c.Next()
@@ -3,6 +3,7 @@ package route
 import (
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/app/service"
+	"arek-muhammadiyah-be/helper"
 	"arek-muhammadiyah-be/middleware"
 	"strconv"
 
@@ -36,7 +37,7 @@ func SetupArticleRoutes(app *fiber.App) {
 		return c.JSON(model.PaginatedResponse{
 			Success:    true,
 			Message:    "Articles retrieved successfully",
-			Data:       articles,
+			Data:       helper.MaskSensitive(c, articles),
 			Pagination: pagination,
 		})
 	})
@@ -54,7 +55,7 @@ func SetupArticleRoutes(app *fiber.App) {
 		return c.JSON(model.Response{
 			Success: true,
 			Message: "Article retrieved successfully",
-			Data:    article,
+			Data:    helper.MaskSensitive(c, article),
 		})
 	})
 
@@ -71,7 +72,7 @@ func SetupArticleRoutes(app *fiber.App) {
 		return c.JSON(model.Response{
 			Success: true,
 			Message: "Article retrieved successfully",
-			Data:    article,
+			Data:    helper.MaskSensitive(c, article),
 		})
 	})
 
@@ -99,7 +100,7 @@ func SetupArticleRoutes(app *fiber.App) {
 		return c.Status(fiber.StatusCreated).JSON(model.Response{
 			Success: true,
 			Message: "Article created successfully",
-			Data:    article,
+			Data:    helper.MaskSensitive(c, article),
 		})
 	})
 
@@ -124,7 +125,7 @@ func SetupArticleRoutes(app *fiber.App) {
 		return c.JSON(model.Response{
 			Success: true,
 			Message: "Article updated successfully",
-			Data:    article,
+			Data:    helper.MaskSensitive(c, article),
 		})
 	})
 
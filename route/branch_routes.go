@@ -0,0 +1,29 @@
+package route
+
+import (
+	"arek-muhammadiyah-be/app/service"
+	"arek-muhammadiyah-be/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupBranchRoutes(app *fiber.App) {
+	branchService := service.NewBranchService()
+
+	branches := app.Group("/api/branches", middleware.Authorization())
+	branches.Get("/", branchService.GetAll)
+	branches.Get("/:id", branchService.GetByID)
+	branches.Get("/:id/officers", branchService.GetOfficers)
+
+	branches.Put("/officers/:termId", middleware.AdminOnly(), branchService.UpdateOfficer)
+	branches.Delete("/officers/:termId", middleware.AdminOnly(), branchService.DeleteOfficer)
+	branches.Post("/", middleware.AdminOnly(), branchService.Create)
+	branches.Put("/:id", middleware.AdminOnly(), branchService.Update)
+	branches.Delete("/:id", middleware.AdminOnly(), branchService.Delete)
+	branches.Post("/:id/officers", middleware.AdminOnly(), branchService.AssignOfficer)
+
+	positions := app.Group("/api/positions", middleware.Authorization())
+	positions.Get("/", branchService.GetPositions)
+	positions.Post("/", middleware.AdminOnly(), branchService.CreatePosition)
+	positions.Put("/:id", middleware.AdminOnly(), branchService.UpdatePosition)
+	positions.Delete("/:id", middleware.AdminOnly(), branchService.DeletePosition)
+}
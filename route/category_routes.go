@@ -1,6 +1,7 @@
 package route
 
 import (
+	"errors"
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/app/repository"
 	"arek-muhammadiyah-be/helper"
@@ -12,6 +13,7 @@ import (
 func SetupCategoryRoutes(app *fiber.App) {
 	categories := app.Group("/api/categories")
 	categoryRepo := repository.NewCategoryRepository()
+	userRepo := repository.NewUserRepository()
 
 	// Public routes
 	categories.Get("/", func(c *fiber.Ctx) error {
@@ -32,7 +34,7 @@ func SetupCategoryRoutes(app *fiber.App) {
 		return c.JSON(model.PaginatedResponse{
 			Success:    true,
 			Message:    "Categories retrieved successfully",
-			Data:       categories,
+			Data:       helper.MaskSensitive(c, categories),
 			Pagination: pagination,
 		})
 	})
@@ -50,11 +52,26 @@ func SetupCategoryRoutes(app *fiber.App) {
 			})
 		}
 
+		if err := validateCategorySLA(userRepo, req.FirstResponseHours, req.ResolutionHours, req.SupervisorID); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		if req.SupervisorID != nil && *req.SupervisorID == "" {
+			req.SupervisorID = nil
+		}
+
 		category := &model.Category{
 			Name:        req.Name,
 			Description: req.Description,
 			Color:       helper.GetStringValue(req.Color, "#10B981"),
 			IsActive:    helper.GetBoolValue(req.IsActive, true),
+
+			FirstResponseHours: req.FirstResponseHours,
+			ResolutionHours:    req.ResolutionHours,
+			SupervisorID:       req.SupervisorID,
 		}
 
 		if err := categoryRepo.Create(category); err != nil {
@@ -70,4 +87,100 @@ func SetupCategoryRoutes(app *fiber.App) {
 			Data:    category,
 		})
 	})
-}
\ No newline at end of file
+
+	// SLA targets and supervisor for tickets in the category
+	categories.Put("/:id/sla", func(c *fiber.Ctx) error {
+		id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+		var req model.CategorySLARequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+
+		if _, err := categoryRepo.GetByID(uint(id)); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(model.Response{
+				Success: false,
+				Message: "Category not found",
+			})
+		}
+
+		if err := validateCategorySLA(userRepo, req.FirstResponseHours, req.ResolutionHours, req.SupervisorID); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		// Missing fields go back to the defaults; an empty supervisor clears it
+		var supervisorID *string
+		if req.SupervisorID != nil && *req.SupervisorID != "" {
+			supervisorID = req.SupervisorID
+		}
+		columns := map[string]interface{}{
+			"first_response_hours": req.FirstResponseHours,
+			"resolution_hours":     req.ResolutionHours,
+			"supervisor_id":        supervisorID,
+		}
+		if err := categoryRepo.UpdateSLA(uint(id), columns); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		category, _ := categoryRepo.GetByID(uint(id))
+		return c.JSON(model.Response{
+			Success: true,
+			Message: "Category SLA updated successfully",
+			Data:    category,
+		})
+	})
+
+	categories.Delete("/:id", func(c *fiber.Ctx) error {
+		id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+		if _, err := categoryRepo.GetByID(uint(id)); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(model.Response{
+				Success: false,
+				Message: "Category not found",
+			})
+		}
+
+		if err := categoryRepo.Delete(uint(id)); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.JSON(model.Response{
+			Success: true,
+			Message: "Category deleted successfully",
+		})
+	})
+}
+
+// validateCategorySLA checks the targets and that the supervisor, when
+// given, is staff who can take escalations.
+func validateCategorySLA(userRepo *repository.UserRepository, firstResponseHours, resolutionHours *int, supervisorID *string) error {
+	if firstResponseHours != nil && *firstResponseHours <= 0 {
+		return errors.New("first_response_hours must be positive")
+	}
+	if resolutionHours != nil && *resolutionHours <= 0 {
+		return errors.New("resolution_hours must be positive")
+	}
+	if firstResponseHours != nil && resolutionHours != nil && *resolutionHours < *firstResponseHours {
+		return errors.New("resolution_hours cannot be shorter than first_response_hours")
+	}
+	if supervisorID != nil && *supervisorID != "" {
+		user, err := userRepo.GetByID(*supervisorID)
+		if err != nil {
+			return errors.New("supervisor not found")
+		}
+		if !helper.IsStaffRole(user.RoleID) {
+			return errors.New("supervisor must be staff")
+		}
+	}
+	return nil
+}
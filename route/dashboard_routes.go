@@ -3,6 +3,7 @@ package route
 import (
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/app/repository"
+	"arek-muhammadiyah-be/helper"
 	"arek-muhammadiyah-be/middleware"
 	"github.com/gofiber/fiber/v2"
 )
@@ -16,15 +17,18 @@ func SetupDashboardRoutes(app *fiber.App) {
 		ticketRepo := repository.NewTicketRepository()
 		villageRepo := repository.NewVillageRepository()
 
+		// Narrow member and ticket figures to a province, regency or district
+		region := helper.ParseRegionFilter(c)
+
 		// Get totals
-		_, totalUsers, _ := userRepo.GetAll(1, 0)
+		_, totalUsers, _ := userRepo.GetAll(1, 0, &model.UserFilter{RegionFilter: region})
 		_, totalArticles, _ := articleRepo.GetAll(1, 0, nil)
-		_, totalTickets, _ := ticketRepo.GetAll(1, 0, nil)
-		villages, _, _ := villageRepo.GetAll(100, 0, true)
+		_, totalTickets, _ := ticketRepo.GetAll(1, 0, nil, &region)
+		villages, _, _ := villageRepo.GetAll(100, 0, true, &region)
 
 		// Get ticket stats
-		ticketStatusCounts, _ := ticketRepo.GetCountByStatus()
-		cardStatusStats, _ := userRepo.GetCardStatusStats()
+		ticketStatusCounts, _ := ticketRepo.GetCountByStatus(&region)
+		cardStatusStats, _ := userRepo.GetCardStatusStats(&region)
 
 		stats := model.DashboardStats{
 			TotalUsers:    totalUsers,
@@ -48,4 +52,33 @@ func SetupDashboardRoutes(app *fiber.App) {
 			Data:    stats,
 		})
 	})
+
+	// Members, approvals and tickets per province, regency, district or
+	// village, e.g. ?level=district&regency_code=35.78
+	dashboard.Get("/regions", func(c *fiber.Ctx) error {
+		regionRepo := repository.NewRegionRepository()
+		region := helper.ParseRegionFilter(c)
+
+		level := c.Query("level", model.RegionLevelRegency)
+		if !model.IsValidRegionLevel(level) {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Invalid region level",
+			})
+		}
+
+		summaries, err := regionRepo.Summary(level, &region)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.JSON(model.Response{
+			Success: true,
+			Message: "Region statistics retrieved successfully",
+			Data:    summaries,
+		})
+	})
 }
\ No newline at end of file
@@ -0,0 +1,23 @@
+package route
+
+import (
+	"arek-muhammadiyah-be/app/service"
+	"arek-muhammadiyah-be/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupDuesRoutes(app *fiber.App) {
+	duesService := service.NewDuesService()
+	dues := app.Group("/api/dues", middleware.Authorization())
+
+	dues.Get("/schedules", duesService.GetSchedules)
+	dues.Get("/me", duesService.GetMyDues)
+
+	dues.Post("/schedules", middleware.AdminOnly(), duesService.CreateSchedule)
+	dues.Put("/schedules/:id", middleware.AdminOnly(), duesService.UpdateSchedule)
+	dues.Delete("/schedules/:id", middleware.AdminOnly(), duesService.DeleteSchedule)
+	dues.Get("/payments", middleware.AdminOnly(), duesService.GetPayments)
+	dues.Post("/payments", middleware.AdminOnly(), duesService.CreatePayment)
+	dues.Get("/arrears/:userId", middleware.AdminOnly(), duesService.GetArrears)
+	dues.Get("/summary", middleware.AdminOnly(), duesService.GetVillageSummary)
+}
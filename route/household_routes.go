@@ -0,0 +1,23 @@
+package route
+
+import (
+	"arek-muhammadiyah-be/app/service"
+	"arek-muhammadiyah-be/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupHouseholdRoutes(app *fiber.App) {
+	householdService := service.NewHouseholdService()
+	households := app.Group("/api/households", middleware.Authorization())
+
+	// Listing and KK lookup expose every family, so they are admin only.
+	// Members may read their own household.
+	households.Get("/", middleware.AdminOnly(), householdService.GetAll)
+	households.Get("/kk/:kk", middleware.AdminOnly(), householdService.GetByKKNumber)
+	households.Get("/:id", householdService.GetByID)
+	households.Post("/", middleware.AdminOnly(), householdService.Create)
+	households.Put("/:id", middleware.AdminOnly(), householdService.Update)
+	households.Delete("/:id", middleware.AdminOnly(), householdService.Delete)
+	households.Post("/:id/members", middleware.AdminOnly(), householdService.AddMember)
+	households.Delete("/:id/members/:userId", middleware.AdminOnly(), householdService.RemoveMember)
+}
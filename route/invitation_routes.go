@@ -0,0 +1,20 @@
+package route
+
+import (
+	"arek-muhammadiyah-be/app/service"
+	"arek-muhammadiyah-be/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupInvitationRoutes(app *fiber.App) {
+	invitationService := service.NewInvitationService()
+
+	auth := app.Group("/api/auth/invitations")
+	auth.Get("/:token", invitationService.Check)
+	auth.Post("/accept", invitationService.Accept)
+
+	invitations := app.Group("/api/invitations", middleware.Authorization(), middleware.AdminOnly())
+	invitations.Get("/", invitationService.GetPending)
+	invitations.Post("/users/:userId", invitationService.Send)
+	invitations.Delete("/:id", invitationService.Revoke)
+}
@@ -0,0 +1,21 @@
+package route
+
+import (
+	"arek-muhammadiyah-be/app/service"
+	"arek-muhammadiyah-be/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupPrivacyRoutes(app *fiber.App) {
+	privacyService := service.NewPrivacyService()
+
+	me := app.Group("/api/me", middleware.Authorization())
+	me.Get("/export", privacyService.ExportMe)
+	me.Get("/erasure-requests", privacyService.GetMyErasureRequests)
+	me.Post("/erasure-requests", privacyService.RequestErasure)
+
+	erasure := app.Group("/api/erasure-requests", middleware.Authorization(), middleware.AdminOnly())
+	erasure.Get("/", privacyService.GetErasureRequests)
+	erasure.Post("/:id/approve", privacyService.ApproveErasure)
+	erasure.Post("/:id/reject", privacyService.RejectErasure)
+}
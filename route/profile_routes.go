@@ -0,0 +1,33 @@
+package route
+
+import (
+	"arek-muhammadiyah-be/app/service"
+	"arek-muhammadiyah-be/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupProfileRoutes(app *fiber.App) {
+	profileService := service.NewProfileService()
+	cardService := service.NewCardService()
+	notificationService := service.NewNotificationService()
+
+	me := app.Group("/api/me", middleware.Authorization())
+	me.Get("/", profileService.GetMe)
+	me.Patch("/", profileService.UpdateMe)
+	me.Post("/photo", profileService.UploadMyPhoto)
+	me.Get("/change-requests", profileService.GetMyChangeRequests)
+	me.Get("/renewals", cardService.GetMyRenewals)
+	me.Post("/renewals", cardService.RequestRenewal)
+	me.Get("/notifications", notificationService.GetMine)
+	me.Post("/notifications/:id/read", notificationService.MarkRead)
+
+	changeRequests := app.Group("/api/profile-change-requests", middleware.Authorization(), middleware.AdminOnly())
+	changeRequests.Get("/", profileService.GetChangeRequests)
+	changeRequests.Post("/:id/approve", profileService.ApproveChangeRequest)
+	changeRequests.Post("/:id/reject", profileService.RejectChangeRequest)
+
+	renewals := app.Group("/api/card-renewals", middleware.Authorization(), middleware.AdminOnly())
+	renewals.Get("/", cardService.GetRenewals)
+	renewals.Post("/:id/approve", cardService.ApproveRenewal)
+	renewals.Post("/:id/reject", cardService.RejectRenewal)
+}
@@ -0,0 +1,17 @@
+package route
+
+import (
+	"arek-muhammadiyah-be/app/service"
+	"arek-muhammadiyah-be/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupReferralRoutes(app *fiber.App) {
+	referralService := service.NewReferralService()
+	referrals := app.Group("/api/referrals", middleware.Authorization())
+
+	referrals.Get("/me/code", referralService.GetMyCode)
+	referrals.Get("/me", referralService.GetMyReferrals)
+	referrals.Get("/leaderboard", referralService.GetLeaderboard)
+	referrals.Get("/leaderboard/villages", referralService.GetVillageLeaderboard)
+}
@@ -0,0 +1,32 @@
+package route
+
+import (
+	"arek-muhammadiyah-be/app/service"
+	"arek-muhammadiyah-be/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupRegionRoutes(app *fiber.App) {
+	regionService := service.NewRegionService()
+	regions := app.Group("/api/regions")
+
+	// Public routes
+	regions.Get("/tree", regionService.GetTree)
+	regions.Get("/provinces", regionService.GetProvinces)
+	regions.Get("/regencies", regionService.GetRegencies)
+	regions.Get("/districts", regionService.GetDistricts)
+
+	// Protected routes
+	regions.Use(middleware.Authorization())
+	regions.Use(middleware.AdminOnly())
+
+	regions.Post("/provinces", regionService.CreateProvince)
+	regions.Put("/provinces/:code", regionService.UpdateProvince)
+	regions.Delete("/provinces/:code", regionService.DeleteProvince)
+	regions.Post("/regencies", regionService.CreateRegency)
+	regions.Put("/regencies/:code", regionService.UpdateRegency)
+	regions.Delete("/regencies/:code", regionService.DeleteRegency)
+	regions.Post("/districts", regionService.CreateDistrict)
+	regions.Put("/districts/:code", regionService.UpdateDistrict)
+	regions.Delete("/districts/:code", regionService.DeleteDistrict)
+}
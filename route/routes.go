@@ -1,6 +1,8 @@
 package route
 
 import (
+	"arek-muhammadiyah-be/config"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -13,13 +15,25 @@ func Setup(app *fiber.App) {
 		})
 	})
 
+	// Uploaded files
+	app.Static(config.AppConfig.UploadURL, config.AppConfig.UploadPath)
+
 	// Setup all routes
 	SetupAuthRoutes(app)
+	SetupInvitationRoutes(app)
 	SetupUserRoutes(app)
+	SetupProfileRoutes(app)
+	SetupPrivacyRoutes(app)
 	SetupArticleRoutes(app)
 	SetupTicketRoutes(app)
+	SetupRegionRoutes(app)
 	SetupVillageRoutes(app)
+	SetupBranchRoutes(app)
 	SetupDocumentRoutes(app)
+	SetupHouseholdRoutes(app)
+	SetupDuesRoutes(app)
+	SetupReferralRoutes(app)
 	SetupCategoryRoutes(app)
 	SetupDashboardRoutes(app)
+	SetupTrashRoutes(app)
 }
\ No newline at end of file
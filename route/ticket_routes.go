@@ -1,8 +1,10 @@
 package route
 
 import (
+	"errors"
 	"arek-muhammadiyah-be/app/model"
 	"arek-muhammadiyah-be/app/service"
+	"arek-muhammadiyah-be/helper"
 	"arek-muhammadiyah-be/middleware"
 	"strconv"
 
@@ -28,7 +30,8 @@ func SetupTicketRoutes(app *fiber.App) {
 			status = &s
 		}
 
-		tickets, pagination, err := ticketService.GetAllTickets(page, limit, status)
+		region := helper.ParseRegionFilter(c)
+		tickets, pagination, err := ticketService.GetAllTickets(page, limit, status, &region)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
 				Success: false,
@@ -39,7 +42,7 @@ func SetupTicketRoutes(app *fiber.App) {
 		return c.JSON(model.PaginatedResponse{
 			Success:    true,
 			Message:    "Tickets retrieved successfully",
-			Data:       tickets,
+			Data:       helper.MaskSensitive(c, tickets),
 			Pagination: pagination,
 		})
 	})
@@ -61,14 +64,15 @@ func SetupTicketRoutes(app *fiber.App) {
 		return c.JSON(model.PaginatedResponse{
 			Success:    true,
 			Message:    "User tickets retrieved successfully",
-			Data:       tickets,
+			Data:       helper.MaskSensitive(c, tickets),
 			Pagination: pagination,
 		})
 	})
 
 	// Get ticket statistics (admin only)
 	tickets.Get("/stats", middleware.AdminOnly(), func(c *fiber.Ctx) error {
-		stats, err := ticketService.GetTicketStats()
+		region := helper.ParseRegionFilter(c)
+		stats, err := ticketService.GetTicketStats(&region)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
 				Success: false,
@@ -83,20 +87,241 @@ func SetupTicketRoutes(app *fiber.App) {
 		})
 	})
 
+	// Tickets assigned to the calling staff member
+	tickets.Get("/assigned", middleware.AdminOnly(), func(c *fiber.Ctx) error {
+		userID := c.Locals("user_id").(string)
+		page, _ := strconv.Atoi(c.Query("page", "1"))
+		limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+		var status *model.TicketStatus
+		if statusStr := c.Query("status"); statusStr != "" {
+			s := model.TicketStatus(statusStr)
+			status = &s
+		}
+
+		tickets, pagination, err := ticketService.GetAssignedTickets(userID, page, limit, status)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.JSON(model.PaginatedResponse{
+			Success:    true,
+			Message:    "Assigned tickets retrieved successfully",
+			Data:       helper.MaskSensitive(c, tickets),
+			Pagination: pagination,
+		})
+	})
+
+	// Open and overdue counts per assignee (admin only)
+	tickets.Get("/workload", middleware.AdminOnly(), func(c *fiber.Ctx) error {
+		workload, err := ticketService.GetWorkload()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.JSON(model.Response{
+			Success: true,
+			Message: "Ticket workload retrieved successfully",
+			Data:    workload,
+		})
+	})
+
+	// Auto-assignment rules (admin only)
+	rules := tickets.Group("/assignment-rules", middleware.AdminOnly())
+
+	rules.Get("/", func(c *fiber.Ctx) error {
+		list, err := ticketService.GetAssignmentRules()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.JSON(model.Response{
+			Success: true,
+			Message: "Assignment rules retrieved successfully",
+			Data:    helper.MaskSensitive(c, list),
+		})
+	})
+
+	rules.Post("/", func(c *fiber.Ctx) error {
+		var req model.TicketAssignmentRuleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+
+		rule, err := ticketService.CreateAssignmentRule(&req)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(model.Response{
+			Success: true,
+			Message: "Assignment rule created successfully",
+			Data:    helper.MaskSensitive(c, rule),
+		})
+	})
+
+	rules.Put("/:ruleId", func(c *fiber.Ctx) error {
+		id, _ := strconv.ParseUint(c.Params("ruleId"), 10, 32)
+		var req model.TicketAssignmentRuleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+
+		rule, err := ticketService.UpdateAssignmentRule(uint(id), &req)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.JSON(model.Response{
+			Success: true,
+			Message: "Assignment rule updated successfully",
+			Data:    helper.MaskSensitive(c, rule),
+		})
+	})
+
+	rules.Delete("/:ruleId", func(c *fiber.Ctx) error {
+		id, _ := strconv.ParseUint(c.Params("ruleId"), 10, 32)
+		if err := ticketService.DeleteAssignmentRule(uint(id)); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.JSON(model.Response{
+			Success: true,
+			Message: "Assignment rule deleted successfully",
+		})
+	})
+
 	tickets.Get("/:id", func(c *fiber.Ctx) error {
 		id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
-		ticket, err := ticketService.GetTicketByID(uint(id))
+		userID := c.Locals("user_id").(string)
+		ticket, err := ticketService.GetTicketByID(uint(id), userID, helper.IsStaff(c))
 		if err != nil {
-			return c.Status(fiber.StatusNotFound).JSON(model.Response{
+			return c.Status(ticketErrorStatus(err)).JSON(model.Response{
 				Success: false,
-				Message: "Ticket not found",
+				Message: err.Error(),
 			})
 		}
 
 		return c.JSON(model.Response{
 			Success: true,
 			Message: "Ticket retrieved successfully",
-			Data:    ticket,
+			Data:    helper.MaskSensitive(c, ticket),
+		})
+	})
+
+	// Message thread, open to the reporter and staff
+	tickets.Get("/:id/messages", func(c *fiber.Ctx) error {
+		id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+		userID := c.Locals("user_id").(string)
+
+		messages, err := ticketService.GetMessages(uint(id), userID, helper.IsStaff(c))
+		if err != nil {
+			return c.Status(ticketErrorStatus(err)).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.JSON(model.Response{
+			Success: true,
+			Message: "Ticket messages retrieved successfully",
+			Data:    helper.MaskSensitive(c, messages),
+		})
+	})
+
+	tickets.Post("/:id/messages", func(c *fiber.Ctx) error {
+		id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+		userID := c.Locals("user_id").(string)
+		var req model.CreateTicketMessageRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+
+		message, err := ticketService.PostMessage(uint(id), userID, helper.IsStaff(c), &req)
+		if err != nil {
+			return c.Status(ticketErrorStatus(err)).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(model.Response{
+			Success: true,
+			Message: "Message posted successfully",
+			Data:    message,
+		})
+	})
+
+	// Status history, open to the reporter and staff
+	tickets.Get("/:id/history", func(c *fiber.Ctx) error {
+		id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+		userID := c.Locals("user_id").(string)
+
+		history, err := ticketService.GetStatusHistory(uint(id), userID, helper.IsStaff(c))
+		if err != nil {
+			return c.Status(ticketErrorStatus(err)).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.JSON(model.Response{
+			Success: true,
+			Message: "Ticket history retrieved successfully",
+			Data:    history,
+		})
+	})
+
+	tickets.Post("/:id/reopen", func(c *fiber.Ctx) error {
+		id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+		userID := c.Locals("user_id").(string)
+		var req model.ReopenTicketRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+
+		ticket, err := ticketService.ReopenTicket(uint(id), userID, helper.IsStaff(c), &req)
+		if err != nil {
+			return c.Status(ticketErrorStatus(err)).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.JSON(model.Response{
+			Success: true,
+			Message: "Ticket reopened successfully",
+			Data:    helper.MaskSensitive(c, ticket),
 		})
 	})
 
@@ -121,7 +346,7 @@ func SetupTicketRoutes(app *fiber.App) {
 		return c.Status(fiber.StatusCreated).JSON(model.Response{
 			Success: true,
 			Message: "Ticket created successfully",
-			Data:    ticket,
+			Data:    helper.MaskSensitive(c, ticket),
 		})
 	})
 
@@ -135,7 +360,8 @@ func SetupTicketRoutes(app *fiber.App) {
 			})
 		}
 
-		ticket, err := ticketService.UpdateTicket(uint(id), &req)
+		userID := c.Locals("user_id").(string)
+		ticket, err := ticketService.UpdateTicket(uint(id), userID, &req)
 		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
 				Success: false,
@@ -146,7 +372,50 @@ func SetupTicketRoutes(app *fiber.App) {
 		return c.JSON(model.Response{
 			Success: true,
 			Message: "Ticket updated successfully",
-			Data:    ticket,
+			Data:    helper.MaskSensitive(c, ticket),
+		})
+	})
+
+	tickets.Put("/:id/assign", middleware.AdminOnly(), func(c *fiber.Ctx) error {
+		id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+		userID := c.Locals("user_id").(string)
+		var req model.AssignTicketRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+
+		ticket, err := ticketService.AssignTicket(uint(id), userID, &req)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.JSON(model.Response{
+			Success: true,
+			Message: "Ticket assigned successfully",
+			Data:    helper.MaskSensitive(c, ticket),
+		})
+	})
+
+	tickets.Post("/:id/auto-assign", middleware.AdminOnly(), func(c *fiber.Ctx) error {
+		id, _ := strconv.ParseUint(c.Params("id"), 10, 32)
+		ticket, err := ticketService.AutoAssignTicket(uint(id))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		return c.JSON(model.Response{
+			Success: true,
+			Message: "Ticket assigned successfully",
+			Data:    helper.MaskSensitive(c, ticket),
 		})
 	})
 
@@ -165,4 +434,14 @@ func SetupTicketRoutes(app *fiber.App) {
 			Message: "Ticket deleted successfully",
 		})
 	})
-}
\ No newline at end of file
+}
+
+func ticketErrorStatus(err error) int {
+	if errors.Is(err, service.ErrTicketForbidden) {
+		return fiber.StatusForbidden
+	}
+	if errors.Is(err, service.ErrTicketNotFound) {
+		return fiber.StatusNotFound
+	}
+	return fiber.StatusBadRequest
+}
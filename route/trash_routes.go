@@ -0,0 +1,15 @@
+package route
+
+import (
+	"arek-muhammadiyah-be/app/service"
+	"arek-muhammadiyah-be/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupTrashRoutes(app *fiber.App) {
+	trashService := service.NewTrashService()
+	trash := app.Group("/api/trash", middleware.Authorization(), middleware.AdminOnly())
+
+	trash.Get("/:type", trashService.GetAll)
+	trash.Post("/:type/:id/restore", trashService.Restore)
+}
@@ -8,14 +8,21 @@ import (
 
 func SetupUserRoutes(app *fiber.App) {
 	userService := service.NewUserService()
+	privacyService := service.NewPrivacyService()
 	users := app.Group("/api/users", middleware.Authorization())
 
 	users.Get("/", userService.GetAll)
 	users.Get("/:id", userService.GetByID)
 	users.Post("/", middleware.AdminOnly(), userService.CreateUser)
 	users.Put("/:id", middleware.AdminOnly(), userService.Update)
+	users.Post("/:id/photo", middleware.AdminOnly(), userService.UploadPhoto)
 	users.Delete("/:id", middleware.AdminOnly(), userService.Delete)
+	users.Post("/:id/suspend", middleware.AdminOnly(), userService.Suspend)
+	users.Post("/:id/reactivate", middleware.AdminOnly(), userService.Reactivate)
 	users.Post("/bulk", middleware.AdminOnly(), userService.BulkCreate)
+	users.Post("/card-status/bulk", middleware.AdminOnly(), userService.BulkUpdateCardStatus)
+	users.Get("/:id/card-history", middleware.AdminOnly(), userService.GetCardStatusHistory)
+	users.Get("/:id/export", middleware.AdminOnly(), privacyService.ExportUser)
 	users.Get("/village/:villageId", userService.GetByVillage)
 	users.Get("/card-status/:status", middleware.AdminOnly(), userService.GetByCardStatus)
 }
\ No newline at end of file
@@ -13,12 +13,19 @@ func SetupVillageRoutes(app *fiber.App) {
 	// Public routes
 	villages.Get("/", villageService.GetAll)
 	villages.Get("/map", villageService.GetWithUserCount)
+	villages.Get("/map/geojson", villageService.GetMapGeoJSON)
 
 	// Protected routes
 	villages.Use(middleware.Authorization())
 	villages.Use(middleware.AdminOnly())
 	
+	villages.Get("/stats", villageService.GetStatsComparison)
+	villages.Get("/:id/stats", villageService.GetStats)
 	villages.Post("/", villageService.Create)
+	villages.Post("/import", villageService.ImportVillages)
+	villages.Post("/bulk-status", villageService.BulkUpdateStatus)
+	villages.Post("/boundaries", villageService.UploadBoundaries)
+	villages.Put("/:id/boundary", villageService.UpdateBoundary)
 	villages.Put("/:id", villageService.Update)
 	villages.Delete("/:id", villageService.Delete)
 }
\ No newline at end of file